@@ -0,0 +1,103 @@
+package drone
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// capability is a feature of the Drone api that only some server versions
+// provide, Drone 1.x rewrote the api and dropped some features of 0.8.
+type capability int
+
+const (
+	// capabilityGated is the approval of the builds of gated repositories.
+	capabilityGated capability = iota
+	// capabilityRepoHooks is the selection of the events a repository builds.
+	capabilityRepoHooks
+	// capabilityRepoSettings are the repository settings added by Drone 1.x.
+	capabilityRepoSettings
+	// capabilitySecretFilters are the events and images a secret is limited
+	// to.
+	capabilitySecretFilters
+	// capabilitySecretPullRequest are the pull request flags of secrets.
+	capabilitySecretPullRequest
+	// capabilityRepoRegistries are the registries of a repository, Drone 1.x
+	// keeps registry credentials in secrets.
+	capabilityRepoRegistries
+)
+
+// legacyCapabilities are the capabilities only provided by Drone 0.8, the
+// others are only provided by Drone 1.x.
+var legacyCapabilities = map[capability]bool{
+	capabilityGated:          true,
+	capabilityRepoHooks:      true,
+	capabilitySecretFilters:  true,
+	capabilityRepoRegistries: true,
+}
+
+// attributeGetter reads the configured attributes of a resource, it is
+// implemented by schema.ResourceData and schema.ResourceDiff.
+type attributeGetter interface {
+	GetOk(key string) (interface{}, bool)
+}
+
+// supports reports whether the server provides a capability, every
+// capability is assumed when the server version is unknown.
+func (c *apiClient) supports(capability capability) bool {
+	version := c.ServerVersion()
+
+	if version == nil {
+		return true
+	}
+
+	major, ok := version.major()
+
+	if !ok {
+		return true
+	}
+
+	return legacyCapabilities[capability] == (major < 1)
+}
+
+// unsupportedAttributes returns the configured attributes whose capability
+// the server does not provide, in order. An attribute set to its zero value
+// cannot be told apart from an unset one, it has no effect either way.
+func unsupportedAttributes(client *apiClient, data attributeGetter, attributes map[string]capability) []string {
+	keys := make([]string, 0, len(attributes))
+
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	unsupported := []string{}
+
+	for _, key := range keys {
+		if _, ok := data.GetOk(key); ok && !client.supports(attributes[key]) {
+			unsupported = append(unsupported, key)
+		}
+	}
+
+	return unsupported
+}
+
+// checkCapabilities returns an error for the first configured attribute
+// whose capability the server does not provide.
+func checkCapabilities(client *apiClient, data attributeGetter, attributes map[string]capability) error {
+	for _, key := range unsupportedAttributes(client, data, attributes) {
+		return fmt.Errorf("Error: `%s` is not supported by Drone %s.", key, client.ServerVersion().series())
+	}
+
+	return nil
+}
+
+// warnCapabilities logs a warning for each configured attribute whose
+// capability the server does not provide, for the attributes the server
+// ignores rather than rejects.
+func warnCapabilities(client *apiClient, data attributeGetter, attributes map[string]capability) {
+	for _, key := range unsupportedAttributes(client, data, attributes) {
+		log.Printf("[WARN] `%s` is not supported by Drone %s, it is ignored", key, client.ServerVersion().series())
+	}
+}
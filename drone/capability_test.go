@@ -0,0 +1,77 @@
+package drone
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestSupports(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+		capability    capability
+		supported     bool
+	}{
+		{"Test gated on 0.8", "0.8.6", capabilityGated, true},
+		{"Test gated on 1.x", "1.10.1", capabilityGated, false},
+		{"Test repository settings on 0.8", "0.8.6", capabilityRepoSettings, false},
+		{"Test repository settings on 1.x", "1.10.1", capabilityRepoSettings, true},
+		{"Test repository registries on 1.x", "1.10.1", capabilityRepoRegistries, false},
+		{"Test pull request flags on 2.x", "2.0.0", capabilitySecretPullRequest, true},
+		{"Test unknown version", "latest", capabilityGated, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client := newClient("http://drone.example.com", http.DefaultClient)
+			client.versionOnce.Do(func() {
+				client.version = &serverVersion{Version: test.version}
+			})
+
+			if supported := client.supports(test.capability); supported != test.supported {
+				t.Errorf("unexpected support %t", supported)
+			}
+		})
+	}
+}
+
+// testAttributes are configured attributes, as read by checkCapabilities.
+type testAttributes map[string]interface{}
+
+func (a testAttributes) GetOk(key string) (interface{}, bool) {
+	value, ok := a[key]
+	return value, ok
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+		attributes    testAttributes
+		error         string
+	}{
+		{"Test supported attribute", "1.10.1", testAttributes{"protected": true}, ""},
+		{"Test unsupported attribute", "1.10.1", testAttributes{"gated": true}, "^Error: `gated` is not supported by Drone 1.x.$"},
+		{"Test unsupported attribute on 0.8", "0.8.6", testAttributes{"throttle": 2}, "^Error: `throttle` is not supported by Drone 0.8.$"},
+		{"Test first unsupported attribute", "1.10.1", testAttributes{"hooks": []string{"push"}, "gated": true}, "`gated`"},
+		{"Test unconfigured attributes", "1.10.1", testAttributes{}, ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client := newClient("http://drone.example.com", http.DefaultClient)
+			client.versionOnce.Do(func() {
+				client.version = &serverVersion{Version: test.version}
+			})
+
+			err := checkCapabilities(client, test.attributes, repoCapabilities)
+
+			if (test.error != "") && (err == nil) {
+				t.Fatalf("expected error")
+			}
+
+			if (test.error == "") && (err != nil) {
+				t.Fatalf("unexpected error %s", err)
+			}
+
+			if err != nil && !regexp.MustCompile(test.error).MatchString(err.Error()) {
+				t.Errorf("unexpected error %s", err)
+			}
+		})
+	}
+}
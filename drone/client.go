@@ -0,0 +1,564 @@
+package drone
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/drone/drone-go/drone"
+)
+
+const (
+	pathUserRepos  = "%s/api/user/repos"
+	pathRepo       = "%s/api/repos/%s/%s"
+	pathSecrets    = "%s/api/repos/%s/%s/secrets"
+	pathSecret     = "%s/api/repos/%s/%s/secrets/%s"
+	pathBuilds     = "%s/api/repos/%s/%s/builds"
+	pathPromote    = "%s/api/repos/%s/%s/builds/%d/promote"
+	pathCrons      = "%s/api/repos/%s/%s/cron"
+	pathCron       = "%s/api/repos/%s/%s/cron/%s"
+	pathOrgSecrets = "%s/api/secrets/%s"
+	pathOrgSecret  = "%s/api/secrets/%s/%s"
+	pathTemplates  = "%s/api/templates/%s"
+	pathTemplate   = "%s/api/templates/%s/%s"
+	pathUsers      = "%s/api/users"
+	pathUser       = "%s/api/users/%s"
+	pathVersion    = "%s/version"
+
+	// headerVersion is the response header Drone 0.8 reports its version in.
+	headerVersion = "X-Drone-Version"
+)
+
+type (
+	// repoInfo represents a repository, extending the drone-go repository
+	// with the settings of newer Drone servers.
+	repoInfo struct {
+		drone.Repo
+
+		Active      *bool  `json:"active,omitempty"`
+		UID         string `json:"uid,omitempty"`
+		Namespace   string `json:"namespace,omitempty"`
+		Slug        string `json:"slug,omitempty"`
+		HTTPURL     string `json:"git_http_url,omitempty"`
+		SSHURL      string `json:"git_ssh_url,omitempty"`
+		ConfigPath  string `json:"config_path,omitempty"`
+		Protected   bool   `json:"protected"`
+		IgnoreForks bool   `json:"ignore_forks"`
+		IgnorePulls bool   `json:"ignore_pull_requests"`
+		CancelPulls bool   `json:"auto_cancel_pull_requests"`
+		CancelPush  bool   `json:"auto_cancel_pushes"`
+		Throttle    int64  `json:"throttle"`
+		Counter     int64  `json:"counter"`
+		Signer      string `json:"signer,omitempty"`
+	}
+
+	// repoPatch defines a repository patch request, extending the drone-go
+	// patch with the settings of newer Drone servers.
+	repoPatch struct {
+		drone.RepoPatch
+
+		ConfigPath  *string `json:"config_path,omitempty"`
+		Protected   *bool   `json:"protected,omitempty"`
+		IgnoreForks *bool   `json:"ignore_forks,omitempty"`
+		IgnorePulls *bool   `json:"ignore_pull_requests,omitempty"`
+		CancelPulls *bool   `json:"auto_cancel_pull_requests,omitempty"`
+		CancelPush  *bool   `json:"auto_cancel_pushes,omitempty"`
+		Throttle    *int64  `json:"throttle,omitempty"`
+		Counter     *int64  `json:"counter,omitempty"`
+	}
+
+	// cron represents a cron job that triggers builds on a schedule.
+	cron struct {
+		ID       int64  `json:"id,omitempty"`
+		Name     string `json:"name"`
+		Expr     string `json:"expr"`
+		Next     int64  `json:"next,omitempty"`
+		Prev     int64  `json:"prev,omitempty"`
+		Event    string `json:"event,omitempty"`
+		Branch   string `json:"branch"`
+		Disabled bool   `json:"disabled"`
+	}
+
+	// cronPatch defines a cron job patch request.
+	cronPatch struct {
+		Expr     *string `json:"expr,omitempty"`
+		Branch   *string `json:"branch,omitempty"`
+		Disabled *bool   `json:"disabled,omitempty"`
+	}
+
+	// user represents a user account, including the machine account fields
+	// the drone-go client does not expose.
+	user struct {
+		ID      int64  `json:"id,omitempty"`
+		Login   string `json:"login"`
+		Email   string `json:"email"`
+		Avatar  string `json:"avatar_url,omitempty"`
+		Machine bool   `json:"machine"`
+		Admin   bool   `json:"admin"`
+		Active  bool   `json:"active"`
+		Token   string `json:"token,omitempty"`
+	}
+
+	// orgSecret represents a secret shared by every repository in a
+	// namespace.
+	orgSecret struct {
+		ID              int64  `json:"id,omitempty"`
+		Namespace       string `json:"namespace"`
+		Name            string `json:"name"`
+		Data            string `json:"data,omitempty"`
+		PullRequest     bool   `json:"pull_request"`
+		PullRequestPush bool   `json:"pull_request_push"`
+	}
+
+	// secretInfo represents a repository secret, extending the drone-go
+	// secret with the fields of newer Drone servers. Drone 1.x replaced the
+	// events and images of a secret with the pull request flags.
+	secretInfo struct {
+		drone.Secret
+
+		Data            string `json:"data,omitempty"`
+		PullRequest     bool   `json:"pull_request"`
+		PullRequestPush bool   `json:"pull_request_push"`
+	}
+
+	// serverVersion is the version reported by the Drone server.
+	serverVersion struct {
+		Source  string `json:"source,omitempty"`
+		Version string `json:"version"`
+		Commit  string `json:"commit,omitempty"`
+	}
+
+	// template represents a pipeline template shared by every repository
+	// in a namespace.
+	template struct {
+		ID        int64  `json:"id,omitempty"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+		Data      string `json:"data"`
+	}
+)
+
+// apiClient extends drone.Client with the endpoints of newer Drone servers
+// that the drone-go client does not cover.
+type apiClient struct {
+	drone.Client
+
+	addr   string
+	client *http.Client
+
+	versionOnce sync.Once
+	version     *serverVersion
+}
+
+func newClient(uri string, cli *http.Client) *apiClient {
+	return &apiClient{
+		Client: drone.NewClient(uri, cli),
+		addr:   strings.TrimSuffix(uri, "/"),
+		client: cli,
+	}
+}
+
+// RepoInfo returns a repository by name.
+func (c *apiClient) RepoInfo(owner, name string) (*repoInfo, error) {
+	out := new(repoInfo)
+	uri := fmt.Sprintf(pathRepo, c.addr, owner, name)
+	err := c.do("GET", uri, nil, out)
+	out.normalize()
+	return out, err
+}
+
+// RepoUpdate updates a repository.
+func (c *apiClient) RepoUpdate(owner, name string, in *repoPatch) (*repoInfo, error) {
+	out := new(repoInfo)
+	uri := fmt.Sprintf(pathRepo, c.addr, owner, name)
+	err := c.do("PATCH", uri, in, out)
+	out.normalize()
+	return out, err
+}
+
+// RepoInfoList returns a list of all repositories to which the user has
+// explicit access in the host system.
+func (c *apiClient) RepoInfoList() ([]*repoInfo, error) {
+	var out []*repoInfo
+	uri := fmt.Sprintf(pathUserRepos, c.addr)
+	err := c.do("GET", uri, nil, &out)
+	for _, repo := range out {
+		repo.normalize()
+	}
+	return out, err
+}
+
+// RepoListSync synchronizes the repositories with the remote system and
+// returns the updated list.
+func (c *apiClient) RepoListSync() ([]*repoInfo, error) {
+	var out []*repoInfo
+	uri := fmt.Sprintf(pathUserRepos, c.addr)
+	err := c.do("POST", uri, nil, &out)
+	for _, repo := range out {
+		repo.normalize()
+	}
+	return out, err
+}
+
+// active reports whether the repository is active, older Drone servers only
+// return active repositories.
+func (r *repoInfo) active() bool {
+	return r.Active == nil || *r.Active
+}
+
+// normalize fills the fields reported under different names by older and
+// newer Drone servers, so either can be read the same way.
+func (r *repoInfo) normalize() {
+	if r.Owner == "" {
+		r.Owner = r.Namespace
+	}
+	if r.Namespace == "" {
+		r.Namespace = r.Owner
+	}
+	if r.FullName == "" && r.Owner != "" {
+		r.FullName = fmt.Sprintf("%s/%s", r.Owner, r.Name)
+	}
+	if r.Slug == "" {
+		r.Slug = r.FullName
+	}
+	if r.HTTPURL == "" {
+		r.HTTPURL = r.Clone
+	}
+	if r.ConfigPath == "" {
+		r.ConfigPath = r.Config
+	}
+}
+
+// BuildCreate creates a new build for the branch or commit, passing the
+// params to the pipeline as environment variables.
+func (c *apiClient) BuildCreate(owner, name, commit, branch string, params map[string]string) (*drone.Build, error) {
+	out := new(drone.Build)
+	val := url.Values{}
+	for key, value := range params {
+		val.Set(key, value)
+	}
+	if commit != "" {
+		val.Set("commit", commit)
+	}
+	if branch != "" {
+		val.Set("branch", branch)
+	}
+	uri := fmt.Sprintf(pathBuilds, c.addr, owner, name)
+	err := c.do("POST", uri+"?"+val.Encode(), nil, out)
+	return out, err
+}
+
+// Promote promotes a build to the target environment.
+func (c *apiClient) Promote(owner, name string, build int, target string, params map[string]string) (*drone.Build, error) {
+	out := new(drone.Build)
+	val := url.Values{}
+	for key, value := range params {
+		val.Set(key, value)
+	}
+	val.Set("target", target)
+	uri := fmt.Sprintf(pathPromote, c.addr, owner, name, build)
+	err := c.do("POST", uri+"?"+val.Encode(), nil, out)
+	return out, err
+}
+
+// Cron returns a cron job by name.
+func (c *apiClient) Cron(owner, name, id string) (*cron, error) {
+	out := new(cron)
+	uri := fmt.Sprintf(pathCron, c.addr, owner, name, url.PathEscape(id))
+	err := c.do("GET", uri, nil, out)
+	return out, err
+}
+
+// CronList returns a list of all repository cron jobs.
+func (c *apiClient) CronList(owner, name string) ([]*cron, error) {
+	var out []*cron
+	uri := fmt.Sprintf(pathCrons, c.addr, owner, name)
+	err := c.do("GET", uri, nil, &out)
+	return out, err
+}
+
+// CronCreate creates a cron job.
+func (c *apiClient) CronCreate(owner, name string, in *cron) (*cron, error) {
+	out := new(cron)
+	uri := fmt.Sprintf(pathCrons, c.addr, owner, name)
+	err := c.do("POST", uri, in, out)
+	return out, err
+}
+
+// CronUpdate updates a cron job.
+func (c *apiClient) CronUpdate(owner, name, id string, in *cronPatch) (*cron, error) {
+	out := new(cron)
+	uri := fmt.Sprintf(pathCron, c.addr, owner, name, url.PathEscape(id))
+	err := c.do("PATCH", uri, in, out)
+	return out, err
+}
+
+// CronDelete deletes a cron job.
+func (c *apiClient) CronDelete(owner, name, id string) error {
+	uri := fmt.Sprintf(pathCron, c.addr, owner, name, url.PathEscape(id))
+	return c.do("DELETE", uri, nil, nil)
+}
+
+// UserInfo returns a user account by login, including whether it is a
+// machine account.
+func (c *apiClient) UserInfo(login string) (*user, error) {
+	out := new(user)
+	uri := fmt.Sprintf(pathUser, c.addr, url.PathEscape(login))
+	err := c.do("GET", uri, nil, out)
+	return out, err
+}
+
+// UserInfoUpdate updates a user account.
+func (c *apiClient) UserInfoUpdate(in *user) (*user, error) {
+	out := new(user)
+	uri := fmt.Sprintf(pathUser, c.addr, url.PathEscape(in.Login))
+	err := c.do("PATCH", uri, in, out)
+	return out, err
+}
+
+// UserInfoList returns a list of all user accounts, including whether they
+// are machine accounts.
+func (c *apiClient) UserInfoList() ([]*user, error) {
+	var out []*user
+	uri := fmt.Sprintf(pathUsers, c.addr)
+	err := c.do("GET", uri, nil, &out)
+	return out, err
+}
+
+// UserCreate creates a user account. The returned user holds the api token
+// of a machine account, the server does not return it again afterwards.
+func (c *apiClient) UserCreate(in *user) (*user, error) {
+	out := new(user)
+	uri := fmt.Sprintf(pathUsers, c.addr)
+	err := c.do("POST", uri, in, out)
+	return out, err
+}
+
+// OrgSecret returns an organization secret by name.
+func (c *apiClient) OrgSecret(namespace, name string) (*orgSecret, error) {
+	out := new(orgSecret)
+	uri := fmt.Sprintf(pathOrgSecret, c.addr, url.PathEscape(namespace), url.PathEscape(name))
+	err := c.do("GET", uri, nil, out)
+	return out, err
+}
+
+// OrgSecretList returns a list of all secrets in the namespace.
+func (c *apiClient) OrgSecretList(namespace string) ([]*orgSecret, error) {
+	var out []*orgSecret
+	uri := fmt.Sprintf(pathOrgSecrets, c.addr, url.PathEscape(namespace))
+	err := c.do("GET", uri, nil, &out)
+	return out, err
+}
+
+// OrgSecretCreate creates an organization secret.
+func (c *apiClient) OrgSecretCreate(namespace string, in *orgSecret) (*orgSecret, error) {
+	out := new(orgSecret)
+	uri := fmt.Sprintf(pathOrgSecrets, c.addr, url.PathEscape(namespace))
+	err := c.do("POST", uri, in, out)
+	return out, err
+}
+
+// OrgSecretUpdate updates an organization secret.
+func (c *apiClient) OrgSecretUpdate(namespace string, in *orgSecret) (*orgSecret, error) {
+	out := new(orgSecret)
+	uri := fmt.Sprintf(pathOrgSecret, c.addr, url.PathEscape(namespace), url.PathEscape(in.Name))
+	err := c.do("PATCH", uri, in, out)
+	return out, err
+}
+
+// OrgSecretDelete deletes an organization secret.
+func (c *apiClient) OrgSecretDelete(namespace, name string) error {
+	uri := fmt.Sprintf(pathOrgSecret, c.addr, url.PathEscape(namespace), url.PathEscape(name))
+	return c.do("DELETE", uri, nil, nil)
+}
+
+// Template returns a template by name.
+func (c *apiClient) Template(namespace, name string) (*template, error) {
+	out := new(template)
+	uri := fmt.Sprintf(pathTemplate, c.addr, url.PathEscape(namespace), url.PathEscape(name))
+	err := c.do("GET", uri, nil, out)
+	return out, err
+}
+
+// TemplateList returns a list of all templates in the namespace.
+func (c *apiClient) TemplateList(namespace string) ([]*template, error) {
+	var out []*template
+	uri := fmt.Sprintf(pathTemplates, c.addr, url.PathEscape(namespace))
+	err := c.do("GET", uri, nil, &out)
+	return out, err
+}
+
+// TemplateCreate creates a template.
+func (c *apiClient) TemplateCreate(namespace string, in *template) (*template, error) {
+	out := new(template)
+	uri := fmt.Sprintf(pathTemplates, c.addr, url.PathEscape(namespace))
+	err := c.do("POST", uri, in, out)
+	return out, err
+}
+
+// TemplateUpdate updates a template.
+func (c *apiClient) TemplateUpdate(namespace string, in *template) (*template, error) {
+	out := new(template)
+	uri := fmt.Sprintf(pathTemplate, c.addr, url.PathEscape(namespace), url.PathEscape(in.Name))
+	err := c.do("PATCH", uri, in, out)
+	return out, err
+}
+
+// TemplateDelete deletes a template.
+func (c *apiClient) TemplateDelete(namespace, name string) error {
+	uri := fmt.Sprintf(pathTemplate, c.addr, url.PathEscape(namespace), url.PathEscape(name))
+	return c.do("DELETE", uri, nil, nil)
+}
+
+// SecretInfo returns a repository secret by name.
+func (c *apiClient) SecretInfo(owner, name, secret string) (*secretInfo, error) {
+	out := new(secretInfo)
+	uri := fmt.Sprintf(pathSecret, c.addr, owner, name, url.PathEscape(secret))
+	err := c.do("GET", uri, nil, out)
+	return out, err
+}
+
+// SecretInfoList returns a list of all repository secrets, including the
+// pull request flags of newer servers.
+func (c *apiClient) SecretInfoList(owner, name string) ([]*secretInfo, error) {
+	var out []*secretInfo
+	uri := fmt.Sprintf(pathSecrets, c.addr, owner, name)
+	err := c.do("GET", uri, nil, &out)
+	return out, err
+}
+
+// SecretInfoCreate creates a repository secret, the value is sent in the
+// fields of both 0.8 and 1.x servers.
+func (c *apiClient) SecretInfoCreate(owner, name string, in *secretInfo) (*secretInfo, error) {
+	out := new(secretInfo)
+	in.Data = in.Value
+	uri := fmt.Sprintf(pathSecrets, c.addr, owner, name)
+	err := c.do("POST", uri, in, out)
+	return out, err
+}
+
+// SecretInfoUpdate updates a repository secret, an empty value keeps the
+// current value.
+func (c *apiClient) SecretInfoUpdate(owner, name string, in *secretInfo) (*secretInfo, error) {
+	out := new(secretInfo)
+	in.Data = in.Value
+	uri := fmt.Sprintf(pathSecret, c.addr, owner, name, url.PathEscape(in.Name))
+	err := c.do("PATCH", uri, in, out)
+	return out, err
+}
+
+// SecretInfoDelete deletes a repository secret.
+func (c *apiClient) SecretInfoDelete(owner, name, secret string) error {
+	uri := fmt.Sprintf(pathSecret, c.addr, owner, name, url.PathEscape(secret))
+	return c.do("DELETE", uri, nil, nil)
+}
+
+// ServerVersion returns the version of the Drone server, nil when the server
+// does not report it. The version is requested once.
+func (c *apiClient) ServerVersion() *serverVersion {
+	c.versionOnce.Do(func() {
+		c.version = c.requestVersion()
+	})
+
+	return c.version
+}
+
+// requestVersion requests the version of the Drone server, falling back to
+// the version header that older servers set on every response.
+func (c *apiClient) requestVersion() *serverVersion {
+	resp, err := c.client.Get(fmt.Sprintf(pathVersion, c.addr))
+
+	if err != nil {
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	version := new(serverVersion)
+
+	if resp.StatusCode == http.StatusOK {
+		json.NewDecoder(resp.Body).Decode(version)
+	}
+
+	if version.Version == "" {
+		version.Version = resp.Header.Get(headerVersion)
+	}
+
+	if version.Version == "" {
+		return nil
+	}
+
+	return version
+}
+
+// major returns the major version of the server, e.g. 1 for 1.10.1.
+func (v *serverVersion) major() (int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(v.Version, "v"), ".", 2)
+
+	major, err := strconv.Atoi(parts[0])
+
+	return major, err == nil
+}
+
+// series returns the release series of the server, e.g. 1.x for 1.10.1 and
+// 0.8 for 0.8.6.
+func (v *serverVersion) series() string {
+	if major, ok := v.major(); ok && major >= 1 {
+		return fmt.Sprintf("%d.x", major)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(v.Version, "v"), ".", 3)
+
+	if len(parts) < 2 {
+		return v.Version
+	}
+
+	return parts[0] + "." + parts[1]
+}
+
+// do makes an http request, reporting failures in the same format as the
+// drone-go client.
+func (c *apiClient) do(method, uri string, in, out interface{}) error {
+	var body bytes.Buffer
+
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, uri, &body)
+
+	if err != nil {
+		return err
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode > http.StatusPartialContent {
+		message, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("client error %d: %s", resp.StatusCode, string(message))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
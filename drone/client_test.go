@@ -0,0 +1,140 @@
+package drone
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerVersionMajor(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+		major         int
+		is_error      bool
+	}{
+		{"Test 1.x version", "1.10.1", 1, false},
+		{"Test 0.8 version", "0.8.6", 0, false},
+		{"Test version with a prefix", "v2.0.0", 2, false},
+		{"Test major version only", "1", 1, false},
+		{"Test invalid version", "latest", 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			major, ok := (&serverVersion{Version: test.version}).major()
+
+			if (test.is_error == true) && ok {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && !ok {
+				t.Errorf("unexpected error")
+			}
+
+			if !test.is_error && test.major != major {
+				t.Errorf("unexpected major version %d", major)
+			}
+		})
+	}
+}
+
+func TestServerVersionSeries(t *testing.T) {
+	for _, test := range []struct {
+		name, version, series string
+	}{
+		{"Test 1.x version", "1.10.1", "1.x"},
+		{"Test 2.x version", "v2.0.0", "2.x"},
+		{"Test 0.8 version", "0.8.6", "0.8"},
+		{"Test 0.8 version with a prefix", "v0.8.0-rc.5", "0.8"},
+		{"Test invalid version", "latest", "latest"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if series := (&serverVersion{Version: test.version}).series(); series != test.series {
+				t.Errorf("unexpected series %s", series)
+			}
+		})
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+	}{
+		{"Test version endpoint", "1.10.1"},
+		{"Test version header", "0.8.6"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer("octocat", testServerToken)
+			server.version = test.version
+			defer server.Close()
+
+			version := newClient(server.URL, http.DefaultClient).ServerVersion()
+
+			if version == nil {
+				t.Fatalf("expected version")
+			}
+
+			if version.Version != test.version {
+				t.Errorf("unexpected version %s", version.Version)
+			}
+		})
+	}
+}
+
+func TestServerVersionUnknown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if version := newClient(server.URL, http.DefaultClient).ServerVersion(); version != nil {
+		t.Errorf("unexpected version %s", version.Version)
+	}
+}
+
+func TestClientEscapedPaths(t *testing.T) {
+	for _, test := range []struct {
+		name, path string
+		call       func(client *apiClient) error
+	}{
+		{"Test secret delete", "/api/repos/octocat/hello-world/secrets/a%2Fb", func(client *apiClient) error {
+			return client.SecretInfoDelete("octocat", "hello-world", "a/b")
+		}},
+		{"Test cron", "/api/repos/octocat/hello-world/cron/a%2Fb", func(client *apiClient) error {
+			_, err := client.Cron("octocat", "hello-world", "a/b")
+			return err
+		}},
+		{"Test cron delete", "/api/repos/octocat/hello-world/cron/a%20b", func(client *apiClient) error {
+			return client.CronDelete("octocat", "hello-world", "a b")
+		}},
+		{"Test org secret", "/api/secrets/octo%23cat/a%2Fb", func(client *apiClient) error {
+			_, err := client.OrgSecret("octo#cat", "a/b")
+			return err
+		}},
+		{"Test org secret update", "/api/secrets/octocat/a%3Fb", func(client *apiClient) error {
+			_, err := client.OrgSecretUpdate("octocat", &orgSecret{Name: "a?b"})
+			return err
+		}},
+		{"Test template create", "/api/templates/octo%3Fcat", func(client *apiClient) error {
+			_, err := client.TemplateCreate("octo?cat", &template{Name: "a.yml"})
+			return err
+		}},
+		{"Test template delete", "/api/templates/octocat/a%2Fb.yml", func(client *apiClient) error {
+			return client.TemplateDelete("octocat", "a/b.yml")
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var path string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.EscapedPath()
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			if err := test.call(newClient(server.URL, http.DefaultClient)); err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+
+			if path != test.path {
+				t.Errorf("unexpected path %s", path)
+			}
+		})
+	}
+}
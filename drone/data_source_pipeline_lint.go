@@ -0,0 +1,50 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"strings"
+)
+
+func dataSourcePipelineLint() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"content": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"pipelines": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+
+		Read: dataSourcePipelineLintRead,
+	}
+}
+
+func dataSourcePipelineLintRead(data *schema.ResourceData, meta interface{}) error {
+	content := data.Get("content").(string)
+
+	names, errs := lintPipeline(content)
+
+	if len(errs) > 0 {
+		messages := []string{}
+
+		for _, err := range errs {
+			messages = append(messages, fmt.Sprintf("  - %s", err))
+		}
+
+		return fmt.Errorf("Error: Invalid pipeline configuration:\n%s", strings.Join(messages, "\n"))
+	}
+
+	data.SetId(fmt.Sprintf("%d", hashcode.String(content)))
+
+	data.Set("pipelines", names)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func testPipelineLintDataSourceConfigBasic(content string) string {
+	return fmt.Sprintf(`
+    data "drone_pipeline_lint" "pipeline" {
+      content = <<EOT
+%sEOT
+    }
+    `, content)
+}
+
+func TestPipelineLintDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testPipelineLintDataSourceConfigBasic(
+					"kind: pipeline\nname: build\nsteps:\n- name: test\n  image: golang\n",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.drone_pipeline_lint.pipeline",
+						"pipelines.#",
+						"1",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_pipeline_lint.pipeline",
+						"pipelines.0",
+						"build",
+					),
+				),
+			},
+			{
+				Config: testPipelineLintDataSourceConfigBasic(
+					"kind: pipeline\nname: build\nsteps:\n- name: test\n  image: golang\n- name: test\n  image: golang\n",
+				),
+				ExpectError: regexp.MustCompile(`duplicate step name "test"`),
+			},
+		},
+	})
+}
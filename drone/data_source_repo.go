@@ -0,0 +1,97 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceRepo() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateRepository,
+			},
+			"scm": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"http_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"link": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"default_branch": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"config_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"visibility": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"private": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"trusted": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"gated": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"timeout": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+
+		Read: dataSourceRepoRead,
+	}
+}
+
+func dataSourceRepoRead(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	repository, err := client.RepoInfo(owner, repo)
+
+	return readRepoData(data, repository, err)
+}
+
+func readRepoData(data *schema.ResourceData, repository *repoInfo, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%d", repository.ID))
+
+	data.Set("repository", fmt.Sprintf("%s/%s", repository.Owner, repository.Name))
+	data.Set("scm", repository.Kind)
+	data.Set("http_url", repository.HTTPURL)
+	data.Set("link", repository.Link)
+	data.Set("default_branch", repository.Branch)
+	data.Set("config_path", repository.ConfigPath)
+	data.Set("visibility", repository.Visibility)
+	data.Set("private", repository.IsPrivate)
+	data.Set("trusted", repository.IsTrusted)
+	data.Set("gated", repository.IsGated)
+	data.Set("timeout", repository.Timeout)
+
+	return nil
+}
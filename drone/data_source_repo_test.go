@@ -0,0 +1,66 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"testing"
+)
+
+func testRepoDataSourceConfigBasic(user, repo string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+      timeout    = 30
+    }
+
+    data "drone_repo" "repo" {
+      repository = "${drone_repo.repo.repository}"
+    }
+    `, user, repo)
+}
+
+func TestRepoDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRepoDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRepoDataSourceConfigBasic(testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.drone_repo.repo",
+						"repository",
+						fmt.Sprintf("%s/repository-1", testDroneUser),
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_repo.repo",
+						"id",
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_repo.repo",
+						"scm",
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_repo.repo",
+						"http_url",
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_repo.repo",
+						"default_branch",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repo.repo",
+						"visibility",
+						"private",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repo.repo",
+						"timeout",
+						"30",
+					),
+				),
+			},
+		},
+	})
+}
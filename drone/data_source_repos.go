@@ -0,0 +1,155 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"path"
+	"regexp"
+	"strings"
+)
+
+func dataSourceRepos() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"sync": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"namespace": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateGlob,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"visibility": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: validation.StringInSlice(
+					[]string{"public", "private", "internal"},
+					false,
+				),
+			},
+			"repositories": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+
+		Read: dataSourceReposRead,
+	}
+}
+
+func dataSourceReposRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	list := client.RepoInfoList
+
+	if data.Get("sync").(bool) {
+		list = client.RepoListSync
+	}
+
+	repositories, err := list()
+
+	if err != nil {
+		return err
+	}
+
+	filter, err := newRepoFilter(data)
+
+	if err != nil {
+		return err
+	}
+
+	slugs := []string{}
+
+	for _, repository := range repositories {
+		if filter.match(repository) {
+			slugs = append(slugs, fmt.Sprintf("%s/%s", repository.Owner, repository.Name))
+		}
+	}
+
+	data.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(slugs, ","))))
+
+	data.Set("repositories", slugs)
+
+	return nil
+}
+
+// repoFilter selects the repositories returned by the drone_repos data
+// source, unset filters match every repository.
+type repoFilter struct {
+	namespace  string
+	name       string
+	nameRegex  *regexp.Regexp
+	active     *bool
+	visibility string
+}
+
+func newRepoFilter(data *schema.ResourceData) (*repoFilter, error) {
+	filter := &repoFilter{
+		namespace:  data.Get("namespace").(string),
+		name:       data.Get("name").(string),
+		visibility: data.Get("visibility").(string),
+	}
+
+	if expr, ok := data.GetOk("name_regex"); ok {
+		nameRegex, err := regexp.Compile(expr.(string))
+
+		if err != nil {
+			return nil, err
+		}
+
+		filter.nameRegex = nameRegex
+	}
+
+	if active, ok := data.GetOkExists("active"); ok {
+		value := active.(bool)
+		filter.active = &value
+	}
+
+	return filter, nil
+}
+
+func (f *repoFilter) match(repository *repoInfo) bool {
+	if f.namespace != "" && repository.Owner != f.namespace {
+		return false
+	}
+
+	if f.name != "" {
+		if ok, _ := path.Match(f.name, repository.Name); !ok {
+			return false
+		}
+	}
+
+	if f.nameRegex != nil && !f.nameRegex.MatchString(repository.Name) {
+		return false
+	}
+
+	if f.active != nil && repository.active() != *f.active {
+		return false
+	}
+
+	if f.visibility != "" && repository.Visibility != f.visibility {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,62 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"testing"
+)
+
+func testReposDataSourceConfigBasic(user string) string {
+	return fmt.Sprintf(`
+    data "drone_repos" "inactive" {
+      sync      = true
+      namespace = "%s"
+      name      = "discovery-*"
+      active    = false
+    }
+
+    data "drone_repos" "regex" {
+      sync       = true
+      name_regex = "^discovery-[13]$"
+    }
+    `, user)
+}
+
+func TestReposDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testReposDataSourceConfigBasic(testDroneUser),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.drone_repos.inactive",
+						"repositories.#",
+						"2",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repos.inactive",
+						"repositories.0",
+						fmt.Sprintf("%s/discovery-1", testDroneUser),
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repos.inactive",
+						"repositories.1",
+						fmt.Sprintf("%s/discovery-2", testDroneUser),
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repos.regex",
+						"repositories.#",
+						"2",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_repos.regex",
+						"repositories.0",
+						"octo-org/discovery-3",
+					),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package drone
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceSelf() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"login": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"avatar": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"admin": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+
+		Read: dataSourceSelfRead,
+	}
+}
+
+func dataSourceSelfRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	user, err := client.Self()
+
+	return readUserData(data, user, err)
+}
@@ -0,0 +1,38 @@
+package drone
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"testing"
+)
+
+var testSelfDataSourceConfig = `
+data "drone_self" "self" {}
+`
+
+func TestSelfDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testSelfDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.drone_self.self",
+						"login",
+						testDroneUser,
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_self.self",
+						"id",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_self.self",
+						"admin",
+						"true",
+					),
+				),
+			},
+		},
+	})
+}
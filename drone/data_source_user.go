@@ -0,0 +1,60 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceUser() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"login": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"avatar": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"admin": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+
+		Read: dataSourceUserRead,
+	}
+}
+
+func dataSourceUserRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	user, err := client.User(data.Get("login").(string))
+
+	return readUserData(data, user, err)
+}
+
+func readUserData(data *schema.ResourceData, user *drone.User, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%d", user.ID))
+
+	data.Set("login", user.Login)
+	data.Set("email", user.Email)
+	data.Set("avatar", user.Avatar)
+	data.Set("admin", user.Admin)
+	data.Set("active", user.Active)
+
+	return nil
+}
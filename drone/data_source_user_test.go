@@ -0,0 +1,50 @@
+package drone
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"testing"
+)
+
+var testUserDataSourceConfig = `
+resource "drone_user" "octocat" {
+  login = "octocat"
+}
+
+data "drone_user" "octocat" {
+  login = "${drone_user.octocat.login}"
+}
+`
+
+func TestUserDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUserDataSourceConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.drone_user.octocat",
+						"login",
+						"octocat",
+					),
+					resource.TestCheckResourceAttrSet(
+						"data.drone_user.octocat",
+						"id",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_user.octocat",
+						"admin",
+						"false",
+					),
+					resource.TestCheckResourceAttr(
+						"data.drone_user.octocat",
+						"active",
+						"true",
+					),
+				),
+			},
+		},
+	})
+}
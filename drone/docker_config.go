@@ -0,0 +1,151 @@
+package drone
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"sort"
+	"strings"
+)
+
+const dockerHubAddress = "docker.io"
+
+// dockerHubAliases are the addresses docker clients use for Docker Hub, all
+// of them are registered with Drone as docker.io.
+var dockerHubAliases = []string{
+	"docker.io",
+	"index.docker.io",
+	"registry-1.docker.io",
+	"registry.hub.docker.com",
+}
+
+// dockerConfig is the subset of a ~/.docker/config.json file holding
+// registry credentials.
+type dockerConfig struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// parseDockerConfig returns a registry for each entry of the auths map of a
+// docker config, ordered by address. The base64 auth field takes precedence
+// over a separate username and password.
+func parseDockerConfig(str string) ([]*drone.Registry, error) {
+	config := dockerConfig{}
+
+	if err := json.Unmarshal([]byte(str), &config); err != nil {
+		return nil, fmt.Errorf("Error: Invalid docker config: %s.", err)
+	}
+
+	if len(config.Auths) == 0 {
+		return nil, fmt.Errorf("Error: Invalid docker config: auths is empty.")
+	}
+
+	registries := []*drone.Registry{}
+	sources := map[string]string{}
+
+	for server, auth := range config.Auths {
+		address := normalizeRegistryAddress(server)
+
+		if address == "" {
+			return nil, fmt.Errorf("Error: Invalid docker config: invalid address %q.", server)
+		}
+
+		if source, ok := sources[address]; ok {
+			return nil, fmt.Errorf(
+				"Error: Invalid docker config: %q and %q are both %s.",
+				source,
+				server,
+				address,
+			)
+		}
+
+		sources[address] = server
+
+		username, password := auth.Username, auth.Password
+
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+
+			if err != nil {
+				return nil, fmt.Errorf("Error: Invalid docker config: auth of %q is not base64.", server)
+			}
+
+			parts := strings.SplitN(string(decoded), ":", 2)
+
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("Error: Invalid docker config: auth of %q is not username:password.", server)
+			}
+
+			username, password = parts[0], parts[1]
+		}
+
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("Error: Invalid docker config: %q has no username and password.", server)
+		}
+
+		registries = append(registries, &drone.Registry{
+			Address:  address,
+			Username: username,
+			Password: password,
+		})
+	}
+
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Address < registries[j].Address
+	})
+
+	return registries, nil
+}
+
+// normalizeRegistryAddress reduces a registry address to the form Drone
+// matches images against, dropping the scheme and api version (e.g.
+// https://index.docker.io/v1/ is docker.io). A path naming a project within
+// the registry is kept (e.g. gcr.io/project).
+func normalizeRegistryAddress(address string) string {
+	address = strings.TrimSpace(address)
+
+	for _, scheme := range []string{"https://", "http://"} {
+		address = strings.TrimPrefix(address, scheme)
+	}
+
+	address = strings.TrimRight(address, "/")
+
+	for _, version := range []string{"/v1", "/v2"} {
+		address = strings.TrimSuffix(address, version)
+	}
+
+	host, path := address, ""
+
+	if index := strings.Index(address, "/"); index != -1 {
+		host, path = address[:index], address[index:]
+	}
+
+	host = strings.ToLower(host)
+
+	if stringInSlice(host, dockerHubAliases) {
+		host = dockerHubAddress
+	}
+
+	return host + path
+}
+
+// suppressRegistryAddressDiff ignores a configured address that normalizes
+// to the address kept in state.
+func suppressRegistryAddressDiff(k, old, new string, data *schema.ResourceData) bool {
+	return old != "" && normalizeRegistryAddress(old) == normalizeRegistryAddress(new)
+}
+
+func validateDockerConfig(v interface{}, k string) (ws []string, es []error) {
+	if _, err := parseDockerConfig(v.(string)); err != nil {
+		es = append(es, err)
+	}
+
+	return
+}
@@ -0,0 +1,103 @@
+package drone
+
+import (
+	"testing"
+)
+
+func TestNormalizeRegistryAddress(t *testing.T) {
+	for _, test := range []struct {
+		name, address, normalized string
+	}{
+		{"Test docker hub v1 url", "https://index.docker.io/v1/", "docker.io"},
+		{"Test docker hub host", "index.docker.io", "docker.io"},
+		{"Test docker hub registry host", "registry-1.docker.io", "docker.io"},
+		{"Test docker hub", "docker.io", "docker.io"},
+		{"Test registry url", "https://gcr.io/v2/", "gcr.io"},
+		{"Test registry host with port", "http://registry.example.com:5000", "registry.example.com:5000"},
+		{"Test registry host case", "Quay.IO", "quay.io"},
+		{"Test registry project", "https://gcr.io/project/", "gcr.io/project"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if normalized := normalizeRegistryAddress(test.address); normalized != test.normalized {
+				t.Errorf("unexpected address %s", normalized)
+			}
+		})
+	}
+}
+
+func TestParseDockerConfig(t *testing.T) {
+	for _, test := range []struct {
+		name, config, address, username, password string
+		is_error                                  bool
+	}{
+		{
+			"Test auth field",
+			`{"auths": {"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}}}`,
+			"docker.io",
+			"user",
+			"pass",
+			false,
+		},
+		{
+			"Test username and password fields",
+			`{"auths": {"gcr.io": {"username": "_json_key", "password": "key"}}}`,
+			"gcr.io",
+			"_json_key",
+			"key",
+			false,
+		},
+		{
+			"Test password containing a colon",
+			`{"auths": {"quay.io": {"auth": "cm9ib3Q6YTpi"}}}`,
+			"quay.io",
+			"robot",
+			"a:b",
+			false,
+		},
+		{"Test invalid json", `{"auths": `, "", "", "", true},
+		{"Test empty auths", `{"auths": {}}`, "", "", "", true},
+		{"Test invalid auth", `{"auths": {"gcr.io": {"auth": "not base64!"}}}`, "", "", "", true},
+		{"Test auth without password", `{"auths": {"gcr.io": {"auth": "dXNlcg=="}}}`, "", "", "", true},
+		{"Test missing credentials", `{"auths": {"gcr.io": {}}}`, "", "", "", true},
+		{
+			"Test duplicate addresses",
+			`{"auths": {"docker.io": {"auth": "dXNlcjpwYXNz"}, "https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}}}`,
+			"",
+			"",
+			"",
+			true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			registries, err := parseDockerConfig(test.config)
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if test.is_error {
+				return
+			}
+
+			if len(registries) != 1 {
+				t.Fatalf("unexpected registries %v", registries)
+			}
+
+			if registries[0].Address != test.address {
+				t.Errorf("unexpected address")
+			}
+
+			if registries[0].Username != test.username {
+				t.Errorf("unexpected username")
+			}
+
+			if registries[0].Password != test.password {
+				t.Errorf("unexpected password")
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package drone
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// errorKind classifies a failed api request.
+type errorKind int
+
+const (
+	errorOther errorKind = iota
+	errorNotFound
+	errorAuth
+	errorConflict
+	errorServer
+)
+
+// clientErrorPattern matches the errors returned by the client for an
+// unsuccessful http status, e.g. "client error 404: Not Found".
+var clientErrorPattern = regexp.MustCompile(`^client error (\d{3}):`)
+
+// errorStatus returns the http status of a failed api request.
+func errorStatus(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	match := clientErrorPattern.FindStringSubmatch(err.Error())
+
+	if match == nil {
+		return 0, false
+	}
+
+	status, _ := strconv.Atoi(match[1])
+
+	return status, true
+}
+
+func classifyError(err error) errorKind {
+	status, ok := errorStatus(err)
+
+	switch {
+	case !ok:
+		return errorOther
+	case status == http.StatusNotFound:
+		return errorNotFound
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return errorAuth
+	case status == http.StatusConflict:
+		return errorConflict
+	case status >= http.StatusInternalServerError:
+		return errorServer
+	}
+
+	return errorOther
+}
+
+func isNotFound(err error) bool {
+	return classifyError(err) == errorNotFound
+}
@@ -0,0 +1,141 @@
+package drone
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		err  error
+		kind errorKind
+	}{
+		{"Test no error", nil, errorOther},
+		{"Test not found", errors.New("client error 404: Not Found"), errorNotFound},
+		{"Test unauthorized", errors.New("client error 401: Unauthorized"), errorAuth},
+		{"Test forbidden", errors.New("client error 403: Forbidden"), errorAuth},
+		{"Test conflict", errors.New("client error 409: Conflict"), errorConflict},
+		{"Test internal server error", errors.New("client error 500: sql: error"), errorServer},
+		{"Test bad gateway", errors.New("client error 502: "), errorServer},
+		{"Test bad request", errors.New("client error 400: Bad Request"), errorOther},
+		{"Test connection error", errors.New("dial tcp: connection refused"), errorOther},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if kind := classifyError(test.err); kind != test.kind {
+				t.Errorf("unexpected kind %d", kind)
+			}
+		})
+	}
+}
+
+// testStatusServer returns a server that answers every request with status.
+func testStatusServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	}))
+}
+
+func TestRepoInactive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"namespace":"octocat","name":"hello-world","active":false}`))
+	}))
+	defer server.Close()
+
+	meta := &providerConfig{client: newClient(server.URL, http.DefaultClient)}
+
+	data := schema.TestResourceDataRaw(t, resourceRepo().Schema, map[string]interface{}{})
+	data.SetId("octocat/hello-world")
+
+	if err := resourceRepoRead(data, meta); err != nil {
+		t.Errorf("unexpected read error: %s", err)
+	}
+
+	if data.Id() != "" {
+		t.Errorf("expected id to be cleared")
+	}
+
+	data.SetId("octocat/hello-world")
+
+	if exists, err := resourceRepoExists(data, meta); exists || err != nil {
+		t.Errorf("expected inactive repository not to exist")
+	}
+}
+
+func TestResourceNotFound(t *testing.T) {
+	notFound := testStatusServer(http.StatusNotFound)
+	defer notFound.Close()
+
+	failing := testStatusServer(http.StatusInternalServerError)
+	defer failing.Close()
+
+	for _, test := range []struct {
+		name     string
+		resource *schema.Resource
+		id       string
+	}{
+		{"Test build", resourceBuild(), "octocat/hello-world/42"},
+		{"Test cron", resourceCron(), "octocat/hello-world/nightly"},
+		{"Test orgsecret", resourceOrgSecret(), "octocat/password"},
+		{"Test promotion", resourcePromotion(), "octocat/hello-world/42"},
+		{"Test registry", resourceRegistry(), "octocat/hello-world/docker.io"},
+		{"Test repo", resourceRepo(), "octocat/hello-world"},
+		{"Test repo secrets policy", resourceRepoSecretsPolicy(), "octocat/hello-world"},
+		{"Test secret", resourceSecret(), "octocat/hello-world/password"},
+		{"Test secrets", resourceSecrets(), "octocat/hello-world"},
+		{"Test template", resourceTemplate(), "octocat/pipeline.yaml"},
+		{"Test user", resourceUser(), "octocat"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			meta := &providerConfig{client: newClient(notFound.URL, http.DefaultClient)}
+
+			data := schema.TestResourceDataRaw(t, test.resource.Schema, map[string]interface{}{})
+			data.SetId(test.id)
+
+			if err := test.resource.Read(data, meta); err != nil {
+				t.Errorf("unexpected read error: %s", err)
+			}
+
+			if data.Id() != "" {
+				t.Errorf("expected id to be cleared")
+			}
+
+			data.SetId(test.id)
+
+			if test.resource.Exists != nil {
+				exists, err := test.resource.Exists(data, meta)
+
+				if err != nil {
+					t.Errorf("unexpected exists error: %s", err)
+				}
+
+				if exists {
+					t.Errorf("expected resource not to exist")
+				}
+			}
+
+			meta = &providerConfig{client: newClient(failing.URL, http.DefaultClient)}
+
+			if err := test.resource.Read(data, meta); err == nil {
+				t.Errorf("expected read error")
+			}
+
+			if data.Id() != test.id {
+				t.Errorf("expected id to be kept")
+			}
+
+			if test.resource.Exists == nil {
+				return
+			}
+
+			if _, err := test.resource.Exists(data, meta); err == nil {
+				t.Errorf("expected exists error")
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package drone
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var invalidNameChars = regexp.MustCompile("[^a-z0-9_-]+")
+
+// Generate writes the configuration of the repositories, secrets, registries
+// and users of an existing Drone server as HCL, along with a script importing
+// them into the Terraform state. It backs the generate command of the
+// provider binary, the server is configured by the same environment
+// variables as the provider.
+func Generate(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	server := flags.String("server", "", "Drone server url (default $DRONE_SERVER)")
+	token := flags.String("token", "", "Drone api token (default $DRONE_TOKEN)")
+	namespace := flags.String("namespace", "", "Only generate the repositories of a namespace")
+	users := flags.Bool("users", true, "Generate the users, listing them requires an admin token")
+	out := flags.String("out", "drone.tf", "File the configuration is written to, - for stdout")
+	script := flags.String("import", "import.sh", "File the import script is written to, - for stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	raw := map[string]interface{}{}
+
+	if *server != "" {
+		raw["server"] = *server
+	}
+
+	if *token != "" {
+		raw["token"] = *token
+	}
+
+	provider := Provider()
+
+	if err := provider.Configure(terraform.NewResourceConfigRaw(raw)); err != nil {
+		return err
+	}
+
+	g := newGenerator(provider.Meta().(*providerConfig).client, *namespace, *users)
+
+	if err := g.generate(); err != nil {
+		return err
+	}
+
+	if err := writeOutput(*out, g.config.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return writeOutput(*script, g.script.Bytes(), 0755)
+}
+
+func writeOutput(path string, content []byte, mode os.FileMode) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	return ioutil.WriteFile(path, content, mode)
+}
+
+// generator renders the resources found on a Drone server, the resource
+// names are derived from the drone names and kept unique.
+type generator struct {
+	client    *apiClient
+	namespace string
+	users     bool
+	names     map[string]bool
+	config    bytes.Buffer
+	script    bytes.Buffer
+	variables bytes.Buffer
+}
+
+func newGenerator(client *apiClient, namespace string, users bool) *generator {
+	g := &generator{
+		client:    client,
+		namespace: namespace,
+		users:     users,
+		names:     map[string]bool{},
+	}
+
+	g.script.WriteString("#!/bin/sh\nset -e\n\n")
+
+	return g
+}
+
+func (g *generator) generate() error {
+	repositories, err := g.client.RepoInfoList()
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(repositories, func(i, j int) bool {
+		a, b := repositories[i], repositories[j]
+
+		return a.Owner < b.Owner || (a.Owner == b.Owner && a.Name < b.Name)
+	})
+
+	for _, repository := range repositories {
+		if !repository.active() || (g.namespace != "" && repository.Owner != g.namespace) {
+			continue
+		}
+
+		if err := g.repo(repository); err != nil {
+			return err
+		}
+	}
+
+	if g.users {
+		users, err := g.client.UserInfoList()
+
+		if classifyError(err) == errorAuth {
+			return fmt.Errorf("Error: Listing users requires an admin token, use -users=false to skip them: %s", err)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(users, func(i, j int) bool {
+			return users[i].Login < users[j].Login
+		})
+
+		for _, user := range users {
+			g.user(user)
+		}
+	}
+
+	g.config.Write(g.variables.Bytes())
+
+	return nil
+}
+
+func (g *generator) repo(repository *repoInfo) error {
+	owner, name := repository.Owner, repository.Name
+	slug := fmt.Sprintf("%s/%s", owner, name)
+
+	resource := g.name("drone_repo", owner, name)
+
+	hooks := []string{}
+
+	if repository.AllowPull {
+		hooks = append(hooks, drone.EventPull)
+	}
+
+	if repository.AllowPush {
+		hooks = append(hooks, drone.EventPush)
+	}
+
+	if repository.AllowDeploy {
+		hooks = append(hooks, drone.EventDeploy)
+	}
+
+	if repository.AllowTag {
+		hooks = append(hooks, drone.EventTag)
+	}
+
+	attributes := [][2]string{
+		{"repository", hclString(slug)},
+		{"visibility", hclString(repository.Visibility)},
+	}
+
+	if g.client.supports(capabilityRepoHooks) {
+		attributes = append(attributes, [2]string{"hooks", hclList(hooks)})
+	}
+
+	if repository.ConfigPath != "" {
+		attributes = append(attributes, [2]string{"config_path", hclString(repository.ConfigPath)})
+	}
+
+	for _, setting := range []struct {
+		key   string
+		value bool
+	}{
+		{"trusted", repository.IsTrusted},
+		{"gated", repository.IsGated},
+		{"protected", repository.Protected},
+		{"ignore_forks", repository.IgnoreForks},
+		{"ignore_pull_requests", repository.IgnorePulls},
+		{"auto_cancel_pull_requests", repository.CancelPulls},
+		{"auto_cancel_pushes", repository.CancelPush},
+	} {
+		if setting.value {
+			attributes = append(attributes, [2]string{setting.key, "true"})
+		}
+	}
+
+	if repository.Timeout != 0 {
+		attributes = append(attributes, [2]string{"timeout", strconv.FormatInt(repository.Timeout, 10)})
+	}
+
+	if repository.Throttle != 0 {
+		attributes = append(attributes, [2]string{"throttle", strconv.FormatInt(repository.Throttle, 10)})
+	}
+
+	g.resource("drone_repo", resource, slug, attributes)
+
+	secrets, err := g.client.SecretInfoList(owner, name)
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	for _, secret := range secrets {
+		secretResource := g.name("drone_secret", owner, name, secret.Name)
+
+		attributes := [][2]string{
+			{"repository", fmt.Sprintf("drone_repo.%s.repository", resource)},
+			{"name", hclString(secret.Name)},
+			{"value", g.variable(secretResource, fmt.Sprintf("Value of the secret %s of %s", secret.Name, slug))},
+		}
+
+		if g.client.supports(capabilitySecretFilters) {
+			attributes = append(attributes, [2]string{"events", hclList(secret.Events)})
+
+			if len(secret.Images) > 0 {
+				attributes = append(attributes, [2]string{"images", hclList(secret.Images)})
+			}
+		}
+
+		if g.client.supports(capabilitySecretPullRequest) {
+			if secret.PullRequest {
+				attributes = append(attributes, [2]string{"allow_pull_request", "true"})
+			}
+
+			if secret.PullRequestPush {
+				attributes = append(attributes, [2]string{"allow_push_on_pull_request", "true"})
+			}
+		}
+
+		g.resource("drone_secret", secretResource, formatId(owner, name, secret.Name), attributes)
+	}
+
+	registries, err := listRegistries(g.client, owner, name)
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Address < registries[j].Address
+	})
+
+	for _, registry := range registries {
+		registryResource := g.name("drone_registry", owner, name, registry.Address)
+
+		g.resource("drone_registry", registryResource, formatId(owner, name, registry.Address), [][2]string{
+			{"repository", fmt.Sprintf("drone_repo.%s.repository", resource)},
+			{"address", hclString(registry.Address)},
+			{"username", hclString(registry.Username)},
+			{"password", g.variable(registryResource, fmt.Sprintf("Password of the registry %s of %s", registry.Address, slug))},
+		})
+	}
+
+	return nil
+}
+
+func (g *generator) user(account *user) {
+	attributes := [][2]string{
+		{"login", hclString(account.Login)},
+		{"active", strconv.FormatBool(account.Active)},
+	}
+
+	if account.Admin {
+		attributes = append(attributes, [2]string{"admin", "true"})
+	}
+
+	// a machine account is replaced when the flag changes, which rotates its
+	// token.
+	if account.Machine {
+		attributes = append(attributes, [2]string{"machine", "true"})
+	}
+
+	if account.Email != "" {
+		attributes = append(attributes, [2]string{"email", hclString(account.Email)})
+	}
+
+	g.resource("drone_user", g.name("drone_user", account.Login), account.Login, attributes)
+}
+
+// resource writes a resource block and the command importing it.
+func (g *generator) resource(kind, name, id string, attributes [][2]string) {
+	writeBlock(&g.config, fmt.Sprintf("resource %q %q", kind, name), attributes)
+
+	fmt.Fprintf(&g.script, "terraform import %s %s\n", shellString(kind+"."+name), shellString(id))
+}
+
+// variable declares a variable for a value the server does not return,
+// returning the expression referencing it.
+func (g *generator) variable(name, description string) string {
+	name = g.name("variable", name)
+
+	writeBlock(&g.variables, fmt.Sprintf("variable %q", name), [][2]string{
+		{"type", "string"},
+		{"description", hclString(description)},
+	})
+
+	return fmt.Sprintf("var.%s", name)
+}
+
+// name returns a unique resource name made of parts, names are lower case
+// with anything but letters, digits, dashes and underscores replaced.
+func (g *generator) name(kind string, parts ...string) string {
+	base := invalidNameChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "_")), "_")
+
+	if base == "" || (base[0] >= '0' && base[0] <= '9') || base[0] == '-' {
+		base = "_" + base
+	}
+
+	name := base
+
+	for i := 2; g.names[kind+"."+name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	g.names[kind+"."+name] = true
+
+	return name
+}
+
+// writeBlock writes a block with its attributes aligned the way terraform fmt
+// aligns them.
+func writeBlock(w io.Writer, header string, attributes [][2]string) {
+	width := 0
+
+	for _, attribute := range attributes {
+		if len(attribute[0]) > width {
+			width = len(attribute[0])
+		}
+	}
+
+	fmt.Fprintf(w, "%s {\n", header)
+
+	for _, attribute := range attributes {
+		fmt.Fprintf(w, "  %-*s = %s\n", width, attribute[0], attribute[1])
+	}
+
+	fmt.Fprint(w, "}\n\n")
+}
+
+// hclString quotes str as an HCL string literal, escaping template sequences.
+func hclString(str string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for i, r := range str {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(str[i+1:], "{"):
+			b.WriteRune(r)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+func hclList(list []string) string {
+	items := []string{}
+
+	for _, item := range list {
+		items = append(items, hclString(item))
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+}
+
+// shellString quotes str for a POSIX shell.
+func shellString(str string) string {
+	return "'" + strings.Replace(str, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,209 @@
+package drone
+
+import (
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	server := newTestServer("octocat", testServerToken)
+	defer server.Close()
+
+	provider := Provider()
+
+	if err := provider.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"server": server.URL,
+		"token":  testServerToken,
+	})); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	client := provider.Meta().(*providerConfig).client
+
+	client.RepoPost("octocat", "hello-world")
+	client.SecretCreate("octocat", "hello-world", &drone.Secret{
+		Name:   "password",
+		Value:  "correct horse battery staple",
+		Events: []string{drone.EventPush},
+	})
+	client.SecretInfoCreate("octocat", "hello-world", &secretInfo{
+		Secret: drone.Secret{
+			Name:  "token",
+			Value: "correct horse battery staple",
+		},
+		PullRequest: true,
+	})
+	client.UserCreate(&user{
+		Login:   "robot",
+		Machine: true,
+		Active:  true,
+	})
+
+	dir, err := ioutil.TempDir("", "generate")
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	err = Generate([]string{
+		"-server", server.URL,
+		"-token", testServerToken,
+		"-out", filepath.Join(dir, "drone.tf"),
+		"-import", filepath.Join(dir, "import.sh"),
+	}, ioutil.Discard)
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	config, _ := ioutil.ReadFile(filepath.Join(dir, "drone.tf"))
+	script, _ := ioutil.ReadFile(filepath.Join(dir, "import.sh"))
+
+	for _, expected := range []string{
+		`resource "drone_repo" "octocat_hello-world" {`,
+		`  repository  = "octocat/hello-world"`,
+		`  timeout     = 60`,
+		`resource "drone_secret" "octocat_hello-world_password" {`,
+		`  repository = drone_repo.octocat_hello-world.repository`,
+		`  value      = var.octocat_hello-world_password`,
+		`resource "drone_secret" "octocat_hello-world_token" {`,
+		`  allow_pull_request = true`,
+		`resource "drone_user" "octocat" {`,
+		`resource "drone_user" "robot" {`,
+		`  machine = true`,
+		`variable "octocat_hello-world_password" {`,
+	} {
+		if !strings.Contains(string(config), expected) {
+			t.Errorf("expected %q in configuration:\n%s", expected, config)
+		}
+	}
+
+	// Drone 1.x has no registries, the repository lists none.
+	if strings.Contains(string(config), "drone_registry") {
+		t.Errorf("unexpected registry in configuration:\n%s", config)
+	}
+
+	// the events of a secret are ignored by Drone 1.x.
+	if strings.Contains(string(config), "events") {
+		t.Errorf("unexpected events in configuration:\n%s", config)
+	}
+
+	for _, expected := range []string{
+		`terraform import 'drone_repo.octocat_hello-world' 'octocat/hello-world'`,
+		`terraform import 'drone_secret.octocat_hello-world_password' 'octocat/hello-world/password'`,
+		`terraform import 'drone_user.octocat' 'octocat'`,
+	} {
+		if !strings.Contains(string(script), expected) {
+			t.Errorf("expected %q in import script:\n%s", expected, script)
+		}
+	}
+}
+
+func TestGenerateRegistries(t *testing.T) {
+	server := newTestServer("octocat", testServerToken)
+	server.version = "0.8.6"
+	defer server.Close()
+
+	provider := Provider()
+
+	if err := provider.Configure(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"server": server.URL,
+		"token":  testServerToken,
+	})); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	client := provider.Meta().(*providerConfig).client
+
+	client.RepoPost("octocat", "hello-world")
+	client.RegistryCreate("octocat", "hello-world", &drone.Registry{
+		Address:  "docker.io",
+		Username: "octocat",
+		Password: "pass",
+	})
+
+	dir, err := ioutil.TempDir("", "generate")
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	err = Generate([]string{
+		"-server", server.URL,
+		"-token", testServerToken,
+		"-out", filepath.Join(dir, "drone.tf"),
+		"-import", filepath.Join(dir, "import.sh"),
+	}, ioutil.Discard)
+
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	config, _ := ioutil.ReadFile(filepath.Join(dir, "drone.tf"))
+	script, _ := ioutil.ReadFile(filepath.Join(dir, "import.sh"))
+
+	for _, expected := range []string{
+		`resource "drone_registry" "octocat_hello-world_docker_io" {`,
+		`  password   = var.octocat_hello-world_docker_io`,
+	} {
+		if !strings.Contains(string(config), expected) {
+			t.Errorf("expected %q in configuration:\n%s", expected, config)
+		}
+	}
+
+	expected := `terraform import 'drone_registry.octocat_hello-world_docker_io' 'octocat/hello-world/docker.io'`
+
+	if !strings.Contains(string(script), expected) {
+		t.Errorf("expected %q in import script:\n%s", expected, script)
+	}
+}
+
+func TestGeneratorName(t *testing.T) {
+	g := newGenerator(nil, "", false)
+
+	for _, test := range []struct {
+		name, kind string
+		parts      []string
+		expected   string
+	}{
+		{"Test name", "drone_repo", []string{"octocat", "hello-world"}, "octocat_hello-world"},
+		{"Test invalid characters", "drone_registry", []string{"octocat", "hello-world", "docker.io"}, "octocat_hello-world_docker_io"},
+		{"Test leading digit", "drone_user", []string{"42"}, "_42"},
+		{"Test duplicate name", "drone_repo", []string{"OctoCat", "hello-world"}, "octocat_hello-world_2"},
+		{"Test duplicate name of another kind", "drone_secret", []string{"octocat", "hello-world"}, "octocat_hello-world"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if name := g.name(test.kind, test.parts...); name != test.expected {
+				t.Errorf("unexpected name %s", name)
+			}
+		})
+	}
+}
+
+func TestHclString(t *testing.T) {
+	for _, test := range []struct {
+		name, str, expected string
+	}{
+		{"Test plain string", "hello", `"hello"`},
+		{"Test quotes", `say "hi"`, `"say \"hi\""`},
+		{"Test newline", "a\nb", `"a\nb"`},
+		{"Test interpolation", "${var.x}", `"$${var.x}"`},
+		{"Test directive", "%{if}", `"%%{if}"`},
+		{"Test dollar", "$HOME", `"$HOME"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if str := hclString(test.str); str != test.expected {
+				t.Errorf("unexpected string %s", str)
+			}
+		})
+	}
+}
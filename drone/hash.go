@@ -0,0 +1,113 @@
+package drone
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const secretHashPrefix = "sha256"
+
+// hashSecretValue returns a salted hash of a secret value, the form in which
+// secret values are kept in state. The salt is kept with the hash so the
+// configured value can be compared against it.
+func hashSecretValue(value string, salt []byte) string {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), value...))
+
+	return fmt.Sprintf(
+		"%s:%s:%s",
+		secretHashPrefix,
+		hex.EncodeToString(salt),
+		hex.EncodeToString(sum[:]),
+	)
+}
+
+// newSecretHash hashes a secret value with a random salt.
+func newSecretHash(value string) (string, error) {
+	salt := make([]byte, 16)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return hashSecretValue(value, salt), nil
+}
+
+// secretHashMatches reports whether hash is the hash of value.
+func secretHashMatches(hash, value string) bool {
+	parts := strings.Split(hash, ":")
+
+	if len(parts) != 3 || parts[0] != secretHashPrefix {
+		return false
+	}
+
+	salt, err := hex.DecodeString(parts[1])
+
+	if err != nil {
+		return false
+	}
+
+	expected := hashSecretValue(value, salt)
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1
+}
+
+// isSecretHash reports whether str is a hash rather than a plain value.
+func isSecretHash(str string) bool {
+	parts := strings.Split(str, ":")
+
+	return len(parts) == 3 && parts[0] == secretHashPrefix
+}
+
+// suppressSecretDiff returns a DiffSuppressFunc that compares a configured
+// secret value against the hash kept in state. The value is written again
+// whenever the named version attribute, a sibling of the value, changes.
+func suppressSecretDiff(version string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, data *schema.ResourceData) bool {
+		if data.HasChange(k[:strings.LastIndex(k, ".")+1] + version) {
+			return false
+		}
+
+		return secretHashMatches(old, new)
+	}
+}
+
+// setSecretHash replaces the secret value held in key with its hash.
+func setSecretHash(data *schema.ResourceData, key string) error {
+	hash, err := newSecretHash(data.Get(key).(string))
+
+	if err != nil {
+		return err
+	}
+
+	return data.Set(key, hash)
+}
+
+// upgradeSecretHashState returns a state upgrade replacing the plain secret
+// values that earlier versions kept in state with their hash.
+func upgradeSecretHashState(keys ...string) schema.StateUpgradeFunc {
+	return func(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+		for _, key := range keys {
+			value, ok := rawState[key].(string)
+
+			if !ok || value == "" || isSecretHash(value) {
+				continue
+			}
+
+			hash, err := newSecretHash(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rawState[key] = hash
+		}
+
+		return rawState, nil
+	}
+}
@@ -0,0 +1,117 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"testing"
+)
+
+func TestSecretHashMatches(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+
+	for _, test := range []struct {
+		name    string
+		hash    string
+		value   string
+		matches bool
+	}{
+		{"same value", hashSecretValue("password", salt), "password", true},
+		{"other value", hashSecretValue("password", salt), "passw0rd", false},
+		{"empty value", hashSecretValue("", salt), "", true},
+		{"plain value", "password", "password", false},
+		{"other prefix", "md5:00:00", "password", false},
+		{"invalid salt", "sha256:zz:00", "password", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := secretHashMatches(test.hash, test.value); matches != test.matches {
+				t.Errorf("expected %t, got %t", test.matches, matches)
+			}
+		})
+	}
+}
+
+func TestNewSecretHash(t *testing.T) {
+	first, err := newSecretHash("password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := newSecretHash("password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first == second {
+		t.Errorf("expected hashes with different salts, got %s twice", first)
+	}
+
+	if !isSecretHash(first) || !secretHashMatches(first, "password") {
+		t.Errorf("expected %s to be a hash of the value", first)
+	}
+}
+
+func TestUpgradeSecretHashState(t *testing.T) {
+	hash := hashSecretValue("password", []byte("salt"))
+
+	for _, test := range []struct {
+		name  string
+		value interface{}
+		check func(interface{}) bool
+	}{
+		{
+			"plain value",
+			"password",
+			func(v interface{}) bool { return secretHashMatches(v.(string), "password") },
+		},
+		{
+			"hashed value",
+			hash,
+			func(v interface{}) bool { return v == hash },
+		},
+		{
+			"empty value",
+			"",
+			func(v interface{}) bool { return v == "" },
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state, err := upgradeSecretHashState("value")(
+				map[string]interface{}{"name": "password", "value": test.value},
+				nil,
+			)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if state["name"] != "password" {
+				t.Errorf("expected other attributes to be kept, got %v", state["name"])
+			}
+
+			if !test.check(state["value"]) {
+				t.Errorf("unexpected value %v", state["value"])
+			}
+		})
+	}
+}
+
+func testCheckSecretHash(name, key, value string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		resource, ok := state.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", name)
+		}
+
+		hash := resource.Primary.Attributes[key]
+
+		if !secretHashMatches(hash, value) {
+			return fmt.Errorf("%s: Attribute '%s' is not a hash of the value, got %q", name, key, hash)
+		}
+
+		return nil
+	}
+}
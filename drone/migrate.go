@@ -0,0 +1,217 @@
+package drone
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"strings"
+)
+
+// resourceSecretV0 is the schema of drone_secret before its value was kept in
+// state as a hash.
+func resourceSecretV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"value": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"images": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"events": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceOrgSecretV0 is the schema of drone_orgsecret before its value was
+// kept in state as a hash.
+func resourceOrgSecretV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"value": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"allow_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"allow_push_on_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// resourceRegistryV0 is the schema of drone_registry before its password was
+// kept in state as a hash.
+func resourceRegistryV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"address": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+// resourceSecretV1 is the schema of drone_secret before its identity was
+// percent-encoded.
+func resourceSecretV1() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"value": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"value_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"value_base64": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"value_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"images": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"events": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceRegistryV1 is the schema of drone_registry before its identity was
+// percent-encoded.
+func resourceRegistryV1() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"address": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"password": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"docker_config_json": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"password_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"registries": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// upgradeIdState returns a state upgrade rewriting an identity of the form
+// owner/repo/name, where the name was kept verbatim, in the escaped form of
+// formatId. The name is a comma separated list when the listKey attribute
+// is set.
+func upgradeIdState(listKey string) schema.StateUpgradeFunc {
+	return func(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+		id, _ := rawState["id"].(string)
+
+		parts := strings.SplitN(id, "/", 3)
+
+		if len(parts) != 3 {
+			return rawState, nil
+		}
+
+		names := []string{parts[2]}
+
+		if list, _ := rawState[listKey].(string); list != "" {
+			names = strings.Split(parts[2], ",")
+		}
+
+		rawState["id"] = formatId(parts[0], parts[1], names...)
+
+		return rawState, nil
+	}
+}
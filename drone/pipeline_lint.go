@@ -0,0 +1,301 @@
+package drone
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"sort"
+	"strings"
+)
+
+const defaultPipelineName = "default"
+
+var validPipelineKinds = []string{
+	"pipeline",
+	"secret",
+	"signature",
+	"template",
+}
+
+var validPipelineTypes = []string{
+	"docker",
+	"kubernetes",
+	"exec",
+	"ssh",
+	"digitalocean",
+	"macstadium",
+}
+
+var validTriggerKeys = []string{
+	"action",
+	"branch",
+	"cron",
+	"event",
+	"instance",
+	"paths",
+	"ref",
+	"repo",
+	"status",
+	"target",
+}
+
+// pipelineDocument is the subset of a Drone v1 yaml document checked by
+// lintPipeline, unknown keys are ignored.
+type pipelineDocument struct {
+	Kind      string                 `yaml:"kind"`
+	Type      string                 `yaml:"type"`
+	Name      string                 `yaml:"name"`
+	Steps     []*pipelineStep        `yaml:"steps"`
+	Trigger   map[string]interface{} `yaml:"trigger"`
+	DependsOn []string               `yaml:"depends_on"`
+}
+
+type pipelineStep struct {
+	Name      string   `yaml:"name"`
+	Image     string   `yaml:"image"`
+	DependsOn []string `yaml:"depends_on"`
+}
+
+// lintPipeline parses a multi-document pipeline configuration and returns
+// the names of its pipelines along with every problem found.
+func lintPipeline(content string) (names []string, errs []error) {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+
+	graph := map[string][]string{}
+
+	for index := 1; ; index++ {
+		var document *pipelineDocument
+
+		err := decoder.Decode(&document)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %s", index, err))
+			break
+		}
+
+		if document == nil {
+			continue
+		}
+
+		if document.Kind == "" {
+			errs = append(errs, fmt.Errorf("document %d: kind is required", index))
+			continue
+		}
+
+		if !stringInSlice(document.Kind, validPipelineKinds) {
+			errs = append(errs, fmt.Errorf(
+				"document %d: unknown kind %q, expected one of %s",
+				index,
+				document.Kind,
+				strings.Join(validPipelineKinds, ", "),
+			))
+			continue
+		}
+
+		if document.Kind != "pipeline" {
+			continue
+		}
+
+		if document.Name == "" {
+			document.Name = defaultPipelineName
+		}
+
+		if _, ok := graph[document.Name]; ok {
+			errs = append(errs, fmt.Errorf("pipeline %q: duplicate pipeline name", document.Name))
+			continue
+		}
+
+		names = append(names, document.Name)
+		graph[document.Name] = document.DependsOn
+
+		errs = append(errs, lintPipelineDocument(document)...)
+	}
+
+	for _, name := range names {
+		for _, dependency := range graph[name] {
+			if _, ok := graph[dependency]; !ok {
+				errs = append(errs, fmt.Errorf("pipeline %q: depends_on unknown pipeline %q", name, dependency))
+			}
+		}
+	}
+
+	if cycle := findCycle(names, graph); cycle != nil {
+		errs = append(errs, fmt.Errorf("depends_on cycle between pipelines: %s", strings.Join(cycle, " -> ")))
+	}
+
+	return
+}
+
+func lintPipelineDocument(document *pipelineDocument) (errs []error) {
+	fail := func(format string, a ...interface{}) {
+		errs = append(errs, fmt.Errorf("pipeline %q: %s", document.Name, fmt.Sprintf(format, a...)))
+	}
+
+	pipelineType := document.Type
+
+	if pipelineType == "" {
+		pipelineType = "docker"
+	}
+
+	if !stringInSlice(pipelineType, validPipelineTypes) {
+		fail("unknown type %q, expected one of %s", pipelineType, strings.Join(validPipelineTypes, ", "))
+	}
+
+	if len(document.Steps) == 0 {
+		fail("at least one step is required")
+	}
+
+	steps := []string{}
+	graph := map[string][]string{}
+
+	for index, step := range document.Steps {
+		if step == nil || step.Name == "" {
+			fail("step %d: name is required", index+1)
+			continue
+		}
+
+		if _, ok := graph[step.Name]; ok {
+			fail("duplicate step name %q", step.Name)
+			continue
+		}
+
+		if step.Image == "" && (pipelineType == "docker" || pipelineType == "kubernetes") {
+			fail("step %q: image is required", step.Name)
+		}
+
+		steps = append(steps, step.Name)
+		graph[step.Name] = step.DependsOn
+	}
+
+	for _, name := range steps {
+		for _, dependency := range graph[name] {
+			if _, ok := graph[dependency]; !ok {
+				fail("step %q: depends_on unknown step %q", name, dependency)
+			}
+		}
+	}
+
+	if cycle := findCycle(steps, graph); cycle != nil {
+		fail("depends_on cycle between steps: %s", strings.Join(cycle, " -> "))
+	}
+
+	keys := []string{}
+
+	for key := range document.Trigger {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !stringInSlice(key, validTriggerKeys) {
+			fail("trigger: unknown condition %q", key)
+			continue
+		}
+
+		if !isTriggerCondition(document.Trigger[key]) {
+			fail("trigger: %s must be a string, a list or an include/exclude map", key)
+		}
+	}
+
+	return
+}
+
+// isTriggerCondition reports whether value has one of the forms accepted
+// for a trigger condition: a string, a list of strings or a map with
+// include and exclude lists.
+func isTriggerCondition(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return true
+	case []interface{}:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+
+		return true
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			if key != "include" && key != "exclude" {
+				return false
+			}
+
+			if !isTriggerCondition(item) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// findCycle returns the first dependency cycle in graph, visiting the nodes
+// in order, or nil when the graph is acyclic.
+func findCycle(order []string, graph map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := map[string]int{}
+	path := []string{}
+
+	var visit func(node string) []string
+
+	visit = func(node string) []string {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			for index, name := range path {
+				if name == node {
+					return append(append([]string{}, path[index:]...), node)
+				}
+			}
+		}
+
+		state[node] = visiting
+		path = append(path, node)
+
+		for _, dependency := range graph[node] {
+			if _, ok := graph[dependency]; !ok {
+				continue
+			}
+
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[node] = visited
+
+		return nil
+	}
+
+	for _, node := range order {
+		if cycle := visit(node); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+func stringInSlice(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
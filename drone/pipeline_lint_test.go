@@ -0,0 +1,174 @@
+package drone
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintPipeline(t *testing.T) {
+	for _, test := range []struct {
+		name, content, names, message string
+		is_error                      bool
+	}{
+		{
+			"Test valid pipeline",
+			"kind: pipeline\ntype: docker\nname: build\nsteps:\n- name: test\n  image: golang\n",
+			"build",
+			"",
+			false,
+		},
+		{
+			"Test default pipeline name",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\n",
+			"default",
+			"",
+			false,
+		},
+		{
+			"Test multiple documents",
+			"---\nkind: pipeline\nname: build\nsteps:\n- name: test\n  image: golang\n---\nkind: pipeline\nname: deploy\ndepends_on: [build]\nsteps:\n- name: push\n  image: plugins/docker\n---\nkind: secret\nname: token\n",
+			"build,deploy",
+			"",
+			false,
+		},
+		{
+			"Test exec pipeline without images",
+			"kind: pipeline\ntype: exec\nsteps:\n- name: test\n  commands: [make]\n",
+			"default",
+			"",
+			false,
+		},
+		{
+			"Test trigger conditions",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\ntrigger:\n  branch: master\n  event: [push, tag]\n  ref:\n    include: [refs/tags/*]\n    exclude: [refs/tags/beta*]\n",
+			"default",
+			"",
+			false,
+		},
+		{
+			"Test invalid yaml",
+			"kind: pipeline\nsteps: {",
+			"",
+			"document 1",
+			true,
+		},
+		{
+			"Test missing kind",
+			"name: build\n",
+			"",
+			"kind is required",
+			true,
+		},
+		{
+			"Test unknown kind",
+			"kind: pipline\n",
+			"",
+			`unknown kind "pipline"`,
+			true,
+		},
+		{
+			"Test unknown type",
+			"kind: pipeline\ntype: dokcer\nsteps:\n- name: test\n  image: golang\n",
+			"default",
+			`unknown type "dokcer"`,
+			true,
+		},
+		{
+			"Test missing steps",
+			"kind: pipeline\n",
+			"default",
+			"at least one step is required",
+			true,
+		},
+		{
+			"Test step without name",
+			"kind: pipeline\nsteps:\n- image: golang\n",
+			"default",
+			"step 1: name is required",
+			true,
+		},
+		{
+			"Test step without image",
+			"kind: pipeline\nsteps:\n- name: test\n",
+			"default",
+			`step "test": image is required`,
+			true,
+		},
+		{
+			"Test duplicate step names",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\n- name: test\n  image: node\n",
+			"default",
+			`duplicate step name "test"`,
+			true,
+		},
+		{
+			"Test duplicate pipeline names",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\n---\nkind: pipeline\nsteps:\n- name: test\n  image: golang\n",
+			"default",
+			"duplicate pipeline name",
+			true,
+		},
+		{
+			"Test unknown step dependency",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\n  depends_on: [build]\n",
+			"default",
+			`depends_on unknown step "build"`,
+			true,
+		},
+		{
+			"Test step dependency cycle",
+			"kind: pipeline\nsteps:\n- name: a\n  image: golang\n  depends_on: [c]\n- name: b\n  image: golang\n  depends_on: [a]\n- name: c\n  image: golang\n  depends_on: [b]\n",
+			"default",
+			"depends_on cycle between steps: a -> c -> b -> a",
+			true,
+		},
+		{
+			"Test unknown pipeline dependency",
+			"kind: pipeline\ndepends_on: [build]\nsteps:\n- name: test\n  image: golang\n",
+			"default",
+			`depends_on unknown pipeline "build"`,
+			true,
+		},
+		{
+			"Test pipeline dependency cycle",
+			"kind: pipeline\nname: a\ndepends_on: [b]\nsteps:\n- name: test\n  image: golang\n---\nkind: pipeline\nname: b\ndepends_on: [a]\nsteps:\n- name: test\n  image: golang\n",
+			"a,b",
+			"depends_on cycle between pipelines: a -> b -> a",
+			true,
+		},
+		{
+			"Test unknown trigger condition",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\ntrigger:\n  branches: master\n",
+			"default",
+			`unknown condition "branches"`,
+			true,
+		},
+		{
+			"Test invalid trigger condition",
+			"kind: pipeline\nsteps:\n- name: test\n  image: golang\ntrigger:\n  branch:\n    only: [master]\n",
+			"default",
+			"branch must be a string, a list or an include/exclude map",
+			true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			names, errs := lintPipeline(test.content)
+
+			if (test.is_error == true) && (len(errs) == 0) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (len(errs) != 0) {
+				t.Errorf("unexpected error: %v", errs)
+			}
+
+			if test.is_error && len(errs) != 0 && !strings.Contains(errs[0].Error(), test.message) {
+				t.Errorf("unexpected error: %s", errs[0])
+			}
+
+			if strings.Join(names, ",") != test.names {
+				t.Errorf("unexpected pipelines: %v", names)
+			}
+		})
+	}
+}
@@ -1,10 +1,16 @@
 package drone
 
 import (
+	"context"
 	"fmt"
-	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"golang.org/x/oauth2"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
 )
 
 func Provider() *schema.Provider {
@@ -22,30 +28,158 @@ func Provider() *schema.Provider {
 				Description: "API Token for the drone server",
 				DefaultFunc: schema.EnvDefaultFunc("DRONE_TOKEN", nil),
 			},
+			"ca_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Path to a PEM encoded certificate authority for the drone server",
+				DefaultFunc:   schema.EnvDefaultFunc("DRONE_CA_FILE", nil),
+				ConflictsWith: []string{"ca_pem"},
+			},
+			"ca_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "PEM encoded certificate authority for the drone server",
+				ConflictsWith: []string{"ca_file"},
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "PEM encoded client certificate, or a path to one, for mutual TLS",
+				DefaultFunc: schema.EnvDefaultFunc("DRONE_CLIENT_CERT", nil),
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM encoded client key, or a path to one, for mutual TLS",
+				DefaultFunc: schema.EnvDefaultFunc("DRONE_CLIENT_KEY", nil),
+			},
+			"insecure_skip_verify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Skip verification of the drone server certificate",
+				DefaultFunc: schema.EnvDefaultFunc("DRONE_INSECURE_SKIP_VERIFY", false),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Maximum number of retries for transient api errors",
+				DefaultFunc:  schema.EnvDefaultFunc("DRONE_MAX_RETRIES", 3),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_max_wait": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Description:  "Maximum number of seconds to wait between retries",
+				DefaultFunc:  schema.EnvDefaultFunc("DRONE_RETRY_MAX_WAIT", 30),
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"default_namespace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Namespace of the repositories configured without one",
+				DefaultFunc: schema.EnvDefaultFunc("DRONE_DEFAULT_NAMESPACE", nil),
+				ValidateFunc: validation.StringMatch(
+					regexp.MustCompile("^[^/ ]+$"),
+					"Invalid namespace (e.g. octocat)",
+				),
+			},
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"drone_pipeline_lint": dataSourcePipelineLint(),
+			"drone_repo":          dataSourceRepo(),
+			"drone_repos":         dataSourceRepos(),
+			"drone_self":          dataSourceSelf(),
+			"drone_user":          dataSourceUser(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"drone_registry": resourceRegistry(),
-			"drone_repo":     resourceRepo(),
-			"drone_secret":   resourceSecret(),
-			"drone_user":     resourceUser(),
+			"drone_build":               resourceBuild(),
+			"drone_cron":                resourceCron(),
+			"drone_orgsecret":           resourceOrgSecret(),
+			"drone_promotion":           resourcePromotion(),
+			"drone_registry":            resourceRegistry(),
+			"drone_repo":                resourceRepo(),
+			"drone_repo_secrets_policy": resourceRepoSecretsPolicy(),
+			"drone_secret":              resourceSecret(),
+			"drone_secrets":             resourceSecrets(),
+			"drone_template":            resourceTemplate(),
+			"drone_user":                resourceUser(),
 		},
 		ConfigureFunc: providerConfigureFunc,
 	}
 }
 
+// providerConfig is the configured provider, it is passed to the resources
+// and data sources as meta.
+type providerConfig struct {
+	client           *apiClient
+	defaultNamespace string
+}
+
+// parseRepo parses a configured repository name, a name without a namespace
+// belongs to the default namespace.
+func (c *providerConfig) parseRepo(str string) (user, repo string, err error) {
+	if strings.Contains(str, "/") {
+		return parseRepo(str)
+	}
+
+	if c.defaultNamespace == "" {
+		err = fmt.Errorf("Error: Invalid repository %s, set the provider default_namespace or use the full name (e.g. octocat/%s).", str, str)
+		return
+	}
+
+	return parseRepo(fmt.Sprintf("%s/%s", c.defaultNamespace, str))
+}
+
 func providerConfigureFunc(data *schema.ResourceData) (interface{}, error) {
 	config := new(oauth2.Config)
 
+	tlsConfig, err := newTLSConfig(tlsOptions{
+		caFile:             data.Get("ca_file").(string),
+		caPEM:              data.Get("ca_pem").(string),
+		clientCert:         data.Get("client_cert").(string),
+		clientKey:          data.Get("client_key").(string),
+		insecureSkipVerify: data.Get("insecure_skip_verify").(bool),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	transport := newRetryTransport(
+		newBaseTransport(tlsConfig),
+		data.Get("max_retries").(int),
+		time.Duration(data.Get("retry_max_wait").(int))*time.Second,
+	)
+
 	auther := config.Client(
-		oauth2.NoContext,
+		context.WithValue(
+			oauth2.NoContext,
+			oauth2.HTTPClient,
+			&http.Client{Transport: transport},
+		),
 		&oauth2.Token{AccessToken: data.Get("token").(string)},
 	)
 
-	client := drone.NewClient(data.Get("server").(string), auther)
+	client := newClient(data.Get("server").(string), auther)
 
 	if _, err := client.Self(); err != nil {
+		if classifyError(err) == errorAuth {
+			return nil, fmt.Errorf("drone client failed: the token was rejected: %s", err)
+		}
+
 		return nil, fmt.Errorf("drone client failed: %s", err)
 	}
 
-	return client, nil
+	// the version decides the attributes the resources can manage.
+	if version := client.ServerVersion(); version != nil {
+		log.Printf("[INFO] Drone server version %s", version.Version)
+	} else {
+		log.Printf("[WARN] Drone server version unknown, assuming every attribute is supported")
+	}
+
+	return &providerConfig{
+		client:           client,
+		defaultNamespace: data.Get("default_namespace").(string),
+	}, nil
 }
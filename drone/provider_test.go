@@ -1,13 +1,16 @@
 package drone
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
-	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
+const testServerToken = "terraform-provider-drone-token"
+
 var (
 	testDroneUser string = os.Getenv("DRONE_USER")
 	testProviders map[string]terraform.ResourceProvider
@@ -21,6 +24,30 @@ func init() {
 	}
 }
 
+// TestMain runs the suite against an in-memory Drone server unless
+// DRONE_SERVER points the acceptance tests at a live one.
+func TestMain(m *testing.M) {
+	if os.Getenv("DRONE_SERVER") != "" {
+		os.Exit(m.Run())
+	}
+
+	if testDroneUser == "" {
+		testDroneUser = "terraform-provider-drone"
+	}
+
+	server := newTestServer(testDroneUser, testServerToken)
+
+	os.Setenv("DRONE_SERVER", server.URL)
+	os.Setenv("DRONE_TOKEN", testServerToken)
+	os.Setenv("DRONE_USER", testDroneUser)
+
+	code := m.Run()
+
+	server.Close()
+
+	os.Exit(code)
+}
+
 func TestProvider(t *testing.T) {
 	if err := Provider().InternalValidate(); err != nil {
 		t.Fatalf("err: %s", err)
@@ -31,6 +58,52 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+func TestProviderConfigParseRepo(t *testing.T) {
+	for _, test := range []struct {
+		name, namespace, str, user, repo string
+		is_error                         bool
+	}{
+		{"Test full name", "", "octocat/hello-world", "octocat", "hello-world", false},
+		{"Test full name with a default namespace", "octo-org", "octocat/hello-world", "octocat", "hello-world", false},
+		{"Test name in the default namespace", "octo-org", "hello-world", "octo-org", "hello-world", false},
+		{"Test name without a default namespace", "", "hello-world", "", "", true},
+		{"Test invalid name", "octo-org", "foo/bar/baz", "", "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := &providerConfig{defaultNamespace: test.namespace}
+
+			user, repo, err := config.parseRepo(test.str)
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if test.user != user {
+				t.Errorf("unexpected user")
+			}
+
+			if test.repo != repo {
+				t.Errorf("unexpected repo")
+			}
+		})
+	}
+}
+
+// testProviderConfig configures the provider for a test server, for the tests
+// that need a server of a specific version.
+func testProviderConfig(server *testServer) string {
+	return fmt.Sprintf(`
+    provider "drone" {
+      server = "%s"
+      token  = "%s"
+    }
+    `, server.URL, testServerToken)
+}
+
 func testAccPreCheck(t *testing.T) {
 	if v := os.Getenv("DRONE_SERVER"); v == "" {
 		t.Fatal("DRONE_SERVER must be set for acceptance tests")
@@ -0,0 +1,238 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"strings"
+	"time"
+)
+
+func resourceBuild() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
+			},
+			"branch": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"commit": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressShortCommit,
+			},
+			"sha": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"params": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"wait": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"number": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"link": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"started": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"finished": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: customizeRepositoryDiff,
+
+		Create: resourceBuildCreate,
+		Read:   resourceBuildRead,
+		Update: resourceBuildUpdate,
+		Delete: resourceBuildDelete,
+	}
+}
+
+func resourceBuildCreate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	build, err := client.BuildCreate(
+		owner,
+		repo,
+		data.Get("commit").(string),
+		data.Get("branch").(string),
+		createParams(data),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if data.Get("wait").(bool) {
+		build, err = waitBuild(client, owner, repo, build, data.Timeout(schema.TimeoutCreate))
+
+		// a failed build is still recorded, the resource is tainted so the
+		// next apply starts another build.
+		if err != nil {
+			readBuild(data, owner, repo, build, nil)
+			return err
+		}
+	}
+
+	return readBuild(data, owner, repo, build, nil)
+}
+
+func resourceBuildRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, number, err := parseBuildId(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	build, err := client.Build(owner, repo, number)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readBuild(data, owner, repo, build, err)
+}
+
+func resourceBuildUpdate(data *schema.ResourceData, meta interface{}) error {
+	// only wait can change in place, it applies to new builds.
+	return resourceBuildRead(data, meta)
+}
+
+func resourceBuildDelete(data *schema.ResourceData, meta interface{}) error {
+	// builds are part of the repository history and are kept.
+	return nil
+}
+
+// statusWaitingOnDependencies is the status of a Drone 1.x build whose stages
+// wait on the stages they depend on.
+const statusWaitingOnDependencies = "waiting_on_dependencies"
+
+// waitBuild polls the build until it has finished, failing when it does not
+// succeed. The last state of the build is returned either way.
+func waitBuild(client drone.Client, owner, repo string, build *drone.Build, timeout time.Duration) (*drone.Build, error) {
+	number := build.Number
+
+	err := resource.Retry(timeout, func() *resource.RetryError {
+		current, err := client.Build(owner, repo, number)
+
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+
+		build = current
+
+		switch build.Status {
+		case drone.StatusSuccess:
+			return nil
+		case drone.StatusPending, drone.StatusRunning, statusWaitingOnDependencies:
+			return resource.RetryableError(fmt.Errorf("Build #%d is %s.", number, build.Status))
+		case drone.StatusBlocked:
+			return resource.NonRetryableError(fmt.Errorf(
+				"Error: Build #%d is blocked awaiting approval, approve or decline it in Drone (%s).",
+				number,
+				build.Link,
+			))
+		}
+
+		return resource.NonRetryableError(fmt.Errorf(
+			"Error: Build #%d finished with status %s (%s).",
+			number,
+			build.Status,
+			build.Link,
+		))
+	})
+
+	return build, err
+}
+
+// suppressShortCommit keeps a short sha from replacing an imported build,
+// whose commit is the full sha.
+func suppressShortCommit(key, old, new string, data *schema.ResourceData) bool {
+	return new != "" && strings.HasPrefix(old, new)
+}
+
+func createParams(data *schema.ResourceData) map[string]string {
+	params := map[string]string{}
+
+	for key, value := range data.Get("params").(map[string]interface{}) {
+		params[key] = value.(string)
+	}
+
+	return params
+}
+
+func readBuild(data *schema.ResourceData, owner, repo string, build *drone.Build, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s/%d", owner, repo, build.Number))
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("branch", build.Branch)
+	data.Set("sha", build.Commit)
+
+	// the configured commit is kept, a short sha or a branch name would
+	// otherwise plan another build once the server has resolved it.
+	if data.Get("commit").(string) == "" {
+		data.Set("commit", build.Commit)
+	}
+
+	data.Set("number", build.Number)
+	data.Set("status", build.Status)
+	data.Set("link", build.Link)
+	data.Set("started", build.Started)
+	data.Set("finished", build.Finished)
+
+	return nil
+}
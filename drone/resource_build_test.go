@@ -0,0 +1,196 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func testBuildConfigBasic(user, repo, branch string, wait bool) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_build" "build" {
+      repository = "${drone_repo.repo.repository}"
+      branch     = "%s"
+      wait       = %t
+
+      params = {
+        IMAGE_TAG = "latest"
+      }
+    }
+    `,
+		user,
+		repo,
+		branch,
+		wait,
+	)
+}
+
+func TestBuild(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testBuildConfigBasic(testDroneUser, "repository-1", "master", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_build.build",
+						"branch",
+						"master",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_build.build",
+						"status",
+						"success",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_build.build",
+						"number",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_build.build",
+						"commit",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_build.build",
+						"link",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_build.build",
+						"finished",
+					),
+				),
+			},
+			{
+				ResourceName:            "drone_build.build",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"params", "wait"},
+			},
+		},
+	})
+}
+
+func testBuildConfigCommit(user, repo, commit string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_build" "build" {
+      repository = "${drone_repo.repo.repository}"
+      commit     = "%s"
+    }
+    `,
+		user,
+		repo,
+		commit,
+	)
+}
+
+func TestBuildShortCommit(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testBuildConfigCommit(testDroneUser, "repository-1", "abc1234"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_build.build",
+						"commit",
+						"abc1234",
+					),
+					resource.TestMatchResourceAttr(
+						"drone_build.build",
+						"sha",
+						regexp.MustCompile("^abc1234[0-9a-f]{33}$"),
+					),
+				),
+			},
+			{
+				// the resolved sha does not plan another build.
+				Config:   testBuildConfigCommit(testDroneUser, "repository-1", "abc1234"),
+				PlanOnly: true,
+			},
+			{
+				// an imported build holds the full sha.
+				Config:                  testBuildConfigCommit(testDroneUser, "repository-1", "abc1234"),
+				ResourceName:            "drone_build.build",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"commit", "wait"},
+			},
+		},
+	})
+}
+
+func TestSuppressShortCommit(t *testing.T) {
+	sha := "abc1234def5678abc1234def5678abc1234def56"
+
+	for _, test := range []struct {
+		name, old, new string
+		suppressed     bool
+	}{
+		{"Test short sha", sha, "abc1234", true},
+		{"Test full sha", sha, sha, true},
+		{"Test other sha", sha, "def5678", false},
+		{"Test new build", "", "abc1234", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if suppressShortCommit("commit", test.old, test.new, nil) != test.suppressed {
+				t.Errorf("unexpected suppression of %s", test.new)
+			}
+		})
+	}
+}
+
+func TestBuildFailure(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testBuildConfigBasic(testDroneUser, "repository-1", "failing", true),
+				ExpectError: regexp.MustCompile("finished with status failure"),
+			},
+		},
+	})
+}
+
+func TestBuildWaitingOnDependencies(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testBuildConfigBasic(testDroneUser, "repository-1", "waiting", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_build.build",
+						"status",
+						"success",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestBuildBlocked(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testBuildConfigBasic(testDroneUser, "repository-1", "blocked", true),
+				ExpectError: regexp.MustCompile("blocked awaiting approval"),
+			},
+		},
+	})
+}
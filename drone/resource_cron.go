@@ -0,0 +1,179 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func resourceCron() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"expr": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"branch": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "master",
+			},
+			"disabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: customizeRepositoryDiff,
+
+		Create: resourceCronCreate,
+		Read:   resourceCronRead,
+		Update: resourceCronUpdate,
+		Delete: resourceCronDelete,
+		Exists: resourceCronExists,
+	}
+}
+
+func resourceCronCreate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	job, err := client.CronCreate(owner, repo, createCron(data))
+
+	return readCron(data, owner, repo, job, err)
+}
+
+func resourceCronRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, name, err := parseId(data.Id(), "nightly")
+
+	if err != nil {
+		return err
+	}
+
+	job, err := client.Cron(owner, repo, name)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readCron(data, owner, repo, job, err)
+}
+
+func resourceCronUpdate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	job, err := client.CronUpdate(
+		owner,
+		repo,
+		data.Get("name").(string),
+		createCronPatch(data),
+	)
+
+	return readCron(data, owner, repo, job, err)
+}
+
+func resourceCronDelete(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, name, err := parseId(data.Id(), "nightly")
+
+	if err != nil {
+		return err
+	}
+
+	return client.CronDelete(owner, repo, name)
+}
+
+func resourceCronExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+	client := meta.(*providerConfig).client
+
+	owner, repo, name, err := parseId(data.Id(), "nightly")
+
+	if err != nil {
+		return false, err
+	}
+
+	job, err := client.Cron(owner, repo, name)
+
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return job.Name == name, nil
+}
+
+func createCron(data *schema.ResourceData) (job *cron) {
+	job = &cron{
+		Name:     data.Get("name").(string),
+		Expr:     data.Get("expr").(string),
+		Branch:   data.Get("branch").(string),
+		Disabled: data.Get("disabled").(bool),
+	}
+
+	return
+}
+
+func createCronPatch(data *schema.ResourceData) (patch *cronPatch) {
+	expr := data.Get("expr").(string)
+	branch := data.Get("branch").(string)
+	disabled := data.Get("disabled").(bool)
+
+	patch = &cronPatch{
+		Expr:     &expr,
+		Branch:   &branch,
+		Disabled: &disabled,
+	}
+
+	return
+}
+
+func readCron(data *schema.ResourceData, owner, repo string, job *cron, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(formatId(owner, repo, job.Name))
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("name", job.Name)
+	data.Set("expr", job.Expr)
+	data.Set("branch", job.Branch)
+	data.Set("disabled", job.Disabled)
+
+	return nil
+}
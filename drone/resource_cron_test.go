@@ -0,0 +1,177 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"testing"
+)
+
+func testCronConfigBasic(user, repo, name, expr, branch string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_cron" "cron" {
+      repository = "${drone_repo.repo.repository}"
+      name       = "%s"
+      expr       = "%s"
+      branch     = "%s"
+    }
+    `,
+		user,
+		repo,
+		name,
+		expr,
+		branch,
+	)
+}
+
+func TestCron(t *testing.T) {
+	var id int64
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testCronDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testCronConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"nightly",
+					"@daily",
+					"master",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCronExists("drone_cron.cron", &id),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"repository",
+						fmt.Sprintf("%s/repository-1", testDroneUser),
+					),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"name",
+						"nightly",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"expr",
+						"@daily",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"branch",
+						"master",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"disabled",
+						"false",
+					),
+				),
+			},
+			{
+				Config: testCronConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"nightly",
+					"@hourly",
+					"develop",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCronUpdatedInPlace("drone_cron.cron", &id),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"expr",
+						"@hourly",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_cron.cron",
+						"branch",
+						"develop",
+					),
+				),
+			},
+		},
+	})
+}
+
+func testCronExists(name string, id *int64) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		job, err := testCronLookup(state, name)
+
+		if err != nil {
+			return err
+		}
+
+		*id = job.ID
+
+		return nil
+	}
+}
+
+func testCronUpdatedInPlace(name string, id *int64) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		job, err := testCronLookup(state, name)
+
+		if err != nil {
+			return err
+		}
+
+		if job.ID != *id {
+			return fmt.Errorf("Cron job was recreated: %d != %d", job.ID, *id)
+		}
+
+		return nil
+	}
+}
+
+func testCronLookup(state *terraform.State, name string) (*cron, error) {
+	client := testProvider.Meta().(*providerConfig).client
+
+	resource, ok := state.RootModule().Resources[name]
+
+	if !ok {
+		return nil, fmt.Errorf("Resource not found: %s", name)
+	}
+
+	owner, repo, err := parseRepo(resource.Primary.Attributes["repository"])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Cron(owner, repo, resource.Primary.Attributes["name"])
+}
+
+func testCronDestroy(state *terraform.State) error {
+	client := testProvider.Meta().(*providerConfig).client
+
+	for _, resource := range state.RootModule().Resources {
+		if resource.Type != "drone_cron" {
+			continue
+		}
+
+		owner, repo, err := parseRepo(resource.Primary.Attributes["repository"])
+
+		if err != nil {
+			return err
+		}
+
+		err = client.CronDelete(owner, repo, resource.Primary.Attributes["name"])
+
+		if err == nil {
+			return fmt.Errorf(
+				"Cron job still exists: %s/%s:%s",
+				owner,
+				repo,
+				resource.Primary.Attributes["name"],
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"regexp"
+)
+
+func resourceOrgSecret() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringMatch(
+					regexp.MustCompile("^[^/ ]+$"),
+					"Invalid namespace (e.g. octocat)",
+				),
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"value": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressSecretDiff("value_version"),
+			},
+			"value_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"allow_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"allow_push_on_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceOrgSecretV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeSecretHashState("value"),
+			},
+		},
+
+		Create: resourceOrgSecretCreate,
+		Read:   resourceOrgSecretRead,
+		Update: resourceOrgSecretUpdate,
+		Delete: resourceOrgSecretDelete,
+		Exists: resourceOrgSecretExists,
+	}
+}
+
+func resourceOrgSecretCreate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace := data.Get("namespace").(string)
+
+	secret, err := client.OrgSecretCreate(namespace, createOrgSecret(data))
+
+	if err != nil {
+		return err
+	}
+
+	if err := setSecretHash(data, "value"); err != nil {
+		return err
+	}
+
+	return readOrgSecret(data, namespace, secret, err)
+}
+
+func resourceOrgSecretRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "secret_password")
+
+	if err != nil {
+		return err
+	}
+
+	secret, err := client.OrgSecret(namespace, name)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readOrgSecret(data, namespace, secret, err)
+}
+
+func resourceOrgSecretUpdate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace := data.Get("namespace").(string)
+
+	secret := createOrgSecret(data)
+
+	err := updateSecretValue(data, "value", func(value string) (err error) {
+		secret.Data = value
+		secret, err = client.OrgSecretUpdate(namespace, secret)
+		return
+	})
+
+	return readOrgSecret(data, namespace, secret, err)
+}
+
+func resourceOrgSecretDelete(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "secret_password")
+
+	if err != nil {
+		return err
+	}
+
+	return client.OrgSecretDelete(namespace, name)
+}
+
+func resourceOrgSecretExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "secret_password")
+
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := client.OrgSecret(namespace, name)
+
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return secret.Name == name, nil
+}
+
+func createOrgSecret(data *schema.ResourceData) (secret *orgSecret) {
+	secret = &orgSecret{
+		Namespace:       data.Get("namespace").(string),
+		Name:            data.Get("name").(string),
+		Data:            data.Get("value").(string),
+		PullRequest:     data.Get("allow_pull_request").(bool),
+		PullRequestPush: data.Get("allow_push_on_pull_request").(bool),
+	}
+
+	return
+}
+
+func readOrgSecret(data *schema.ResourceData, namespace string, secret *orgSecret, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s", namespace, secret.Name))
+
+	data.Set("namespace", namespace)
+	data.Set("name", secret.Name)
+	data.Set("allow_pull_request", secret.PullRequest)
+	data.Set("allow_push_on_pull_request", secret.PullRequestPush)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"testing"
+)
+
+func testOrgSecretConfigBasic(namespace, name, value string, pull bool) string {
+	return fmt.Sprintf(`
+    resource "drone_orgsecret" "secret" {
+      namespace          = "%s"
+      name               = "%s"
+      value              = "%s"
+      allow_pull_request = %t
+    }
+    `,
+		namespace,
+		name,
+		value,
+		pull,
+	)
+}
+
+func TestOrgSecret(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testOrgSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testOrgSecretConfigBasic(
+					testDroneUser,
+					"password",
+					"1234567890",
+					false,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_orgsecret.secret",
+						"namespace",
+						testDroneUser,
+					),
+					resource.TestCheckResourceAttr(
+						"drone_orgsecret.secret",
+						"name",
+						"password",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_orgsecret.secret",
+						"allow_pull_request",
+						"false",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_orgsecret.secret",
+						"allow_push_on_pull_request",
+						"false",
+					),
+				),
+			},
+			{
+				Config: testOrgSecretConfigBasic(
+					testDroneUser,
+					"password",
+					"0987654321",
+					true,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretHash(
+						"drone_orgsecret.secret",
+						"value",
+						"0987654321",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_orgsecret.secret",
+						"allow_pull_request",
+						"true",
+					),
+				),
+			},
+		},
+	})
+}
+
+func testOrgSecretDestroy(state *terraform.State) error {
+	client := testProvider.Meta().(*providerConfig).client
+
+	for _, resource := range state.RootModule().Resources {
+		if resource.Type != "drone_orgsecret" {
+			continue
+		}
+
+		err := client.OrgSecretDelete(
+			resource.Primary.Attributes["namespace"],
+			resource.Primary.Attributes["name"],
+		)
+
+		if err == nil {
+			return fmt.Errorf(
+				"Secret still exists: %s:%s",
+				resource.Primary.Attributes["namespace"],
+				resource.Primary.Attributes["name"],
+			)
+		}
+	}
+
+	return nil
+}
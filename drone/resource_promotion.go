@@ -0,0 +1,190 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const latestBuild = "latest"
+
+func resourcePromotion() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
+			},
+			"build": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringMatch(
+					regexp.MustCompile("^([0-9]+|latest)$"),
+					"Invalid build (e.g. 42 or latest)",
+				),
+			},
+			"branch": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"target": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"params": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"source_build": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"number": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"link": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: customizeRepositoryDiff,
+
+		Create: resourcePromotionCreate,
+		Read:   resourcePromotionRead,
+		Delete: resourcePromotionDelete,
+	}
+}
+
+func resourcePromotionCreate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	source, err := promotionSource(client, owner, repo, data.Get("build").(string), data.Get("branch").(string))
+
+	if err != nil {
+		return err
+	}
+
+	build, err := client.Promote(owner, repo, source, data.Get("target").(string), createParams(data))
+
+	if err != nil {
+		return err
+	}
+
+	build, err = waitBuild(client, owner, repo, build, data.Timeout(schema.TimeoutCreate))
+
+	// a failed promotion is still recorded, the resource is tainted so the
+	// next apply promotes again.
+	if err != nil {
+		readPromotion(data, owner, repo, build, nil)
+		return err
+	}
+
+	return readPromotion(data, owner, repo, build, nil)
+}
+
+func resourcePromotionRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, number, err := parseBuildId(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	build, err := client.Build(owner, repo, number)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readPromotion(data, owner, repo, build, err)
+}
+
+func resourcePromotionDelete(data *schema.ResourceData, meta interface{}) error {
+	// a promotion cannot be undone, promote another build to roll back.
+	return nil
+}
+
+// promotionSource returns the number of the build to promote, either the
+// given number or the latest successful build of the branch.
+func promotionSource(client drone.Client, owner, repo, build, branch string) (int, error) {
+	if build != latestBuild {
+		return strconv.Atoi(build)
+	}
+
+	builds, err := client.BuildList(owner, repo)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, b := range builds {
+		if b.Status != drone.StatusSuccess || b.Event == "promote" || b.Event == drone.EventDeploy {
+			continue
+		}
+
+		if branch == "" || b.Branch == branch {
+			return b.Number, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Error: No successful build of %s/%s to promote.", owner, repo)
+}
+
+func readPromotion(data *schema.ResourceData, owner, repo string, build *drone.Build, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s/%d", owner, repo, build.Number))
+
+	// an imported promotion records the build it was promoted from.
+	if data.Get("build").(string) == "" {
+		data.Set("build", strconv.Itoa(build.Parent))
+	}
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("target", build.Deploy)
+	data.Set("source_build", build.Parent)
+	data.Set("number", build.Number)
+	data.Set("status", build.Status)
+	data.Set("link", build.Link)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"strconv"
+	"testing"
+)
+
+func testPromotionConfigBasic(user, repo string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_build" "build" {
+      repository = "${drone_repo.repo.repository}"
+      wait       = true
+    }
+
+    resource "drone_promotion" "staging" {
+      repository = "${drone_repo.repo.repository}"
+      build      = "${drone_build.build.number}"
+      target     = "staging"
+
+      params = {
+        REGION = "eu-west-1"
+      }
+    }
+
+    resource "drone_promotion" "production" {
+      repository = "${drone_repo.repo.repository}"
+      build      = "latest"
+      branch     = "master"
+      target     = "production"
+
+      depends_on = ["drone_promotion.staging"]
+    }
+    `,
+		user,
+		repo,
+	)
+}
+
+func TestPromotion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testPromotionConfigBasic(testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_promotion.staging",
+						"target",
+						"staging",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_promotion.staging",
+						"status",
+						"success",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_promotion.production",
+						"target",
+						"production",
+					),
+					testPromotionSource("drone_promotion.staging", "drone_build.build"),
+					testPromotionSource("drone_promotion.production", "drone_build.build"),
+				),
+			},
+			{
+				ResourceName:            "drone_promotion.staging",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"params"},
+			},
+		},
+	})
+}
+
+// testPromotionSource checks the promotion was promoted from the build.
+func testPromotionSource(name, build string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		promotion, ok := state.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", name)
+		}
+
+		source, ok := state.RootModule().Resources[build]
+
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", build)
+		}
+
+		if promotion.Primary.Attributes["source_build"] != source.Primary.Attributes["number"] {
+			return fmt.Errorf(
+				"Expected %s to be promoted from build %s, got %s",
+				name,
+				source.Primary.Attributes["number"],
+				promotion.Primary.Attributes["source_build"],
+			)
+		}
+
+		if number, _ := strconv.Atoi(promotion.Primary.Attributes["number"]); number == 0 {
+			return fmt.Errorf("Expected %s to record the promotion build number", name)
+		}
+
+		return nil
+	}
+}
@@ -3,36 +3,59 @@ package drone
 import (
 	"fmt"
 	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/terraform/helper/validation"
-	"regexp"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"sort"
 )
 
 func resourceRegistry() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"repository": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-				ValidateFunc: validation.StringMatch(
-					regexp.MustCompile("^[^/ ]+/[^/ ]+$"),
-					"Invalid repository (e.g. octocat/hello-world)",
-				),
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
 			},
 			"address": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{"address", "docker_config_json"},
+				RequiredWith:     []string{"username", "password"},
+				DiffSuppressFunc: suppressRegistryAddressDiff,
 			},
 			"username": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"docker_config_json"},
 			},
 			"password": {
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"docker_config_json"},
+				DiffSuppressFunc: suppressSecretDiff("password_version"),
+			},
+			"docker_config_json": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ExactlyOneOf:     []string{"address", "docker_config_json"},
+				ValidateFunc:     validateDockerConfig,
+				DiffSuppressFunc: suppressSecretDiff("password_version"),
+			},
+			"password_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"registries": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 		},
 
@@ -40,6 +63,22 @@ func resourceRegistry() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceRegistryV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeSecretHashState("password"),
+			},
+			{
+				Version: 1,
+				Type:    resourceRegistryV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeIdState("docker_config_json"),
+			},
+		},
+
+		CustomizeDiff: resourceRegistryCustomizeDiff,
+
 		Create: resourceRegistryCreate,
 		Read:   resourceRegistryRead,
 		Update: resourceRegistryUpdate,
@@ -49,77 +88,307 @@ func resourceRegistry() *schema.Resource {
 }
 
 func resourceRegistryCreate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
 
 	if err != nil {
 		return err
 	}
 
+	if usesDockerConfig(data) {
+		return applyDockerConfig(client, data, owner, repo)
+	}
+
 	registry, err := client.RegistryCreate(owner, repo, createRegistry(data))
 
-	data.Set("password", data.Get("password").(string))
+	if err != nil {
+		return err
+	}
+
+	if err := setSecretHash(data, "password"); err != nil {
+		return err
+	}
 
 	return readRegistry(data, owner, repo, registry, err)
 }
 
 func resourceRegistryRead(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
-	owner, repo, address, err := parseId(data.Id(), "drone.io")
+	owner, repo, addresses, err := parseRegistryId(data)
 
 	if err != nil {
 		return err
 	}
 
-	registry, err := client.Registry(owner, repo, address)
+	if holdsRegistryList(data, addresses) {
+		return resourceDockerConfigRegistriesRead(client, data, owner, repo, addresses)
+	}
+
+	registry, err := client.Registry(owner, repo, addresses[0])
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
 
 	return readRegistry(data, owner, repo, registry, err)
 }
 
+func resourceDockerConfigRegistriesRead(client drone.Client, data *schema.ResourceData, owner, repo string, addresses []string) error {
+	found := []string{}
+
+	for _, address := range addresses {
+		_, err := client.Registry(owner, repo, address)
+
+		if isNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		found = append(found, address)
+	}
+
+	if len(found) == 0 {
+		data.SetId("")
+		return nil
+	}
+
+	// registries deleted outside of terraform are created again by the next
+	// apply, forgetting the hash of the docker config brings up the diff.
+	if len(found) != len(addresses) {
+		data.Set("docker_config_json", "")
+	}
+
+	readDockerConfigRegistries(data, owner, repo, found)
+
+	return nil
+}
+
 func resourceRegistryUpdate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	if usesDockerConfig(data) {
+		return applyDockerConfig(client, data, owner, repo)
+	}
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	_, _, addresses, err := parseRegistryId(data)
 
 	if err != nil {
 		return err
 	}
 
-	registry, err := client.RegistryUpdate(owner, repo, createRegistry(data))
+	// the registry is updated under the address it was created with, which
+	// is not necessarily the normalized form.
+	registry := createRegistry(data)
+	registry.Address = addresses[0]
 
-	data.Set("password", data.Get("password").(string))
+	err = updateSecretValue(data, "password", func(password string) (err error) {
+		registry.Password = password
+		registry, err = client.RegistryUpdate(owner, repo, registry)
+		return
+	})
 
 	return readRegistry(data, owner, repo, registry, err)
 }
 
 func resourceRegistryDelete(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
-	owner, repo, address, err := parseId(data.Id(), "drone.io")
+	owner, repo, addresses, err := parseRegistryId(data)
 
 	if err != nil {
 		return err
 	}
 
-	return client.RegistryDelete(owner, repo, address)
+	if !holdsRegistryList(data, addresses) {
+		return client.RegistryDelete(owner, repo, addresses[0])
+	}
+
+	for _, address := range addresses {
+		if err := client.RegistryDelete(owner, repo, address); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func resourceRegistryExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
+
+	owner, repo, addresses, err := parseRegistryId(data)
+
+	if err != nil {
+		return false, err
+	}
+
+	if holdsRegistryList(data, addresses) {
+		// the registries left are read again, the missing ones are created by
+		// the next apply.
+		for _, address := range addresses {
+			_, err := client.Registry(owner, repo, address)
+
+			if err == nil {
+				return true, nil
+			}
+
+			if !isNotFound(err) {
+				return false, err
+			}
+		}
+
+		return false, nil
+	}
 
-	owner, repo, address, err := parseId(data.Id(), "drone.io")
+	registry, err := client.Registry(owner, repo, addresses[0])
+
+	if isNotFound(err) {
+		return false, nil
+	}
 
 	if err != nil {
 		return false, err
 	}
 
-	registry, err := client.Registry(owner, repo, address)
+	return registry.Address == addresses[0], nil
+}
+
+func resourceRegistryCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeRepositoryDiff(diff, meta); err != nil {
+		return err
+	}
+
+	if diff.Id() == "" || !diff.HasChange("docker_config_json") {
+		return nil
+	}
+
+	if !diff.NewValueKnown("docker_config_json") {
+		return diff.SetNewComputed("registries")
+	}
+
+	config := diff.Get("docker_config_json").(string)
+
+	// replaced by a single registry, address forces a new resource.
+	if config == "" {
+		return nil
+	}
+
+	registries, err := parseDockerConfig(config)
+
+	if err != nil {
+		return err
+	}
+
+	addresses := []string{}
+
+	for _, registry := range registries {
+		addresses = append(addresses, registry.Address)
+	}
+
+	return diff.SetNew("registries", addresses)
+}
+
+// parseRegistryId returns the addresses held by the identity, a single one
+// unless the resource manages the registries of a docker config.
+func parseRegistryId(data *schema.ResourceData) (owner, repo string, addresses []string, err error) {
+	return parseIdList(data.Id(), "drone.io")
+}
+
+// holdsRegistryList reports whether the identity holds the registries of a
+// docker config. A failed apply forgets the docker config and an import does
+// not know it, several addresses in the identity tell the same.
+func holdsRegistryList(data *schema.ResourceData, addresses []string) bool {
+	return len(addresses) > 1 || usesDockerConfig(data)
+}
+
+// usesDockerConfig reports whether the resource manages the registries of a
+// docker config rather than a single registry.
+func usesDockerConfig(data *schema.ResourceData) bool {
+	return data.Get("docker_config_json").(string) != ""
+}
+
+// applyDockerConfig creates or updates a registry for each entry of the
+// docker config and deletes the registries of the entries removed from it.
+func applyDockerConfig(client drone.Client, data *schema.ResourceData, owner, repo string) error {
+	registries, err := parseDockerConfig(data.Get("docker_config_json").(string))
+
+	if err != nil {
+		return err
+	}
+
+	previous, _ := data.GetChange("registries")
+	current := []string{}
+	applied := []string{}
+
+	for _, address := range previous.([]interface{}) {
+		current = append(current, address.(string))
+	}
+
+	// a partial apply keeps every registry that may exist, the docker config
+	// is forgotten so the next apply tries again.
+	fail := func(err error) error {
+		for _, address := range applied {
+			if !stringInSlice(address, current) {
+				current = append(current, address)
+			}
+		}
+
+		sort.Strings(current)
+
+		data.Set("docker_config_json", "")
+		readDockerConfigRegistries(data, owner, repo, current)
+
+		return err
+	}
+
+	for _, registry := range registries {
+		var err error
+
+		if stringInSlice(registry.Address, current) {
+			_, err = client.RegistryUpdate(owner, repo, registry)
+		}
+
+		if !stringInSlice(registry.Address, current) || isNotFound(err) {
+			_, err = client.RegistryCreate(owner, repo, registry)
+		}
+
+		if err != nil {
+			return fail(err)
+		}
+
+		applied = append(applied, registry.Address)
+	}
+
+	for _, address := range current {
+		if stringInSlice(address, applied) {
+			continue
+		}
 
-	exists := (registry.Address == address) && (err == nil)
+		if err := client.RegistryDelete(owner, repo, address); err != nil && !isNotFound(err) {
+			return fail(err)
+		}
+	}
 
-	return exists, err
+	if err := setSecretHash(data, "docker_config_json"); err != nil {
+		return err
+	}
+
+	readDockerConfigRegistries(data, owner, repo, applied)
+
+	return nil
 }
 
 func createRegistry(data *schema.ResourceData) (registry *drone.Registry) {
@@ -137,11 +406,21 @@ func readRegistry(data *schema.ResourceData, owner, repo string, registry *drone
 		return err
 	}
 
-	data.SetId(fmt.Sprintf("%s/%s/%s", owner, repo, registry.Address))
+	data.SetId(formatId(owner, repo, registry.Address))
 
 	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
 	data.Set("address", registry.Address)
 	data.Set("username", registry.Username)
+	data.Set("registries", []string{registry.Address})
 
 	return nil
 }
+
+func readDockerConfigRegistries(data *schema.ResourceData, owner, repo string, addresses []string) {
+	data.SetId(formatId(owner, repo, addresses...))
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("address", "")
+	data.Set("username", "")
+	data.Set("registries", addresses)
+}
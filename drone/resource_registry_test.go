@@ -3,13 +3,17 @@ package drone
 import (
 	"fmt"
 	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 )
 
-func testRegistryConfigBasic(user, repo, address, username, password string) string {
-	return fmt.Sprintf(`
+func testRegistryConfigBasic(server *testServer, user, repo, address, username, password string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
     resource "drone_repo" "repo" {
       repository = "%s/%s"
     }
@@ -30,6 +34,9 @@ func testRegistryConfigBasic(user, repo, address, username, password string) str
 }
 
 func TestRegistry(t *testing.T) {
+	server := testRegistryServer()
+	defer server.Close()
+
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
 		Providers:    testProviders,
@@ -37,6 +44,7 @@ func TestRegistry(t *testing.T) {
 		Steps: []resource.TestStep{
 			{
 				Config: testRegistryConfigBasic(
+					server,
 					testDroneUser,
 					"repository-1",
 					"example.com",
@@ -59,7 +67,7 @@ func TestRegistry(t *testing.T) {
 						"username",
 						"user",
 					),
-					resource.TestCheckResourceAttr(
+					testCheckSecretHash(
 						"drone_registry.registry",
 						"password",
 						"pass",
@@ -70,8 +78,267 @@ func TestRegistry(t *testing.T) {
 	})
 }
 
+func TestRegistryRawAddress(t *testing.T) {
+	server := testRegistryServer()
+	defer server.Close()
+
+	// a registry created with a raw address is updated under that address.
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRegistryDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRegistryConfigBasic(
+					server,
+					testDroneUser,
+					"repository-1",
+					"https://index.docker.io/v1/",
+					"user",
+					"pass",
+				),
+				Check: testRegistryContents("repository-1", map[string]string{
+					"https://index.docker.io/v1/": "user",
+				}),
+			},
+			{
+				Config: testRegistryConfigBasic(
+					server,
+					testDroneUser,
+					"repository-1",
+					"https://index.docker.io/v1/",
+					"robot",
+					"token",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"username",
+						"robot",
+					),
+					testRegistryContents("repository-1", map[string]string{
+						"https://index.docker.io/v1/": "robot",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testRegistryConfigDockerConfig(server *testServer, user, repo, config string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_registry" "registry" {
+      repository         = "${drone_repo.repo.repository}"
+      docker_config_json = <<EOT
+%sEOT
+    }
+    `,
+		user,
+		repo,
+		config,
+	)
+}
+
+func TestRegistryDockerConfig(t *testing.T) {
+	server := testRegistryServer()
+	defer server.Close()
+
+	config := `{"auths": {"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}, "gcr.io": {"username": "_json_key", "password": "key"}}}
+`
+	updated := `{"auths": {"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}, "quay.io": {"username": "robot", "password": "token"}}}
+`
+
+	deleted := func() {
+		client := testProvider.Meta().(*providerConfig).client
+
+		client.RegistryDelete(testDroneUser, "repository-1", "docker.io")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRegistryDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", config),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"registries.#",
+						"2",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"registries.0",
+						"docker.io",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"registries.1",
+						"gcr.io",
+					),
+					testCheckSecretHash(
+						"drone_registry.registry",
+						"docker_config_json",
+						config,
+					),
+					testRegistryContents("repository-1", map[string]string{
+						"docker.io": "user",
+						"gcr.io":    "_json_key",
+					}),
+				),
+			},
+			{
+				Config: testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"registries.#",
+						"2",
+					),
+					testRegistryContents("repository-1", map[string]string{
+						"docker.io": "user",
+						"quay.io":   "robot",
+					}),
+				),
+			},
+			{
+				// registries deleted outside of terraform are created again.
+				PreConfig:          deleted,
+				Config:             testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				Check: resource.ComposeTestCheckFunc(
+					testRegistryContents("repository-1", map[string]string{
+						"docker.io": "user",
+						"quay.io":   "robot",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestRegistryDockerConfigFailedApply(t *testing.T) {
+	server := testRegistryServer()
+	defer server.Close()
+
+	config := `{"auths": {"docker.io": {"auth": "dXNlcjpwYXNz"}, "gcr.io": {"username": "_json_key", "password": "key"}}}
+`
+	updated := `{"auths": {"docker.io": {"auth": "dXNlcjpwYXNz"}, "gcr.io": {"username": "_json_key", "password": "key"}, "quay.io": {"username": "robot", "password": "token"}}}
+`
+
+	// a registry created outside of terraform makes the apply fail.
+	conflict := func() {
+		client := testProvider.Meta().(*providerConfig).client
+
+		client.RegistryCreate(testDroneUser, "repository-1", &drone.Registry{
+			Address:  "quay.io",
+			Username: "other",
+			Password: "other",
+		})
+	}
+
+	resolved := func() {
+		client := testProvider.Meta().(*providerConfig).client
+
+		client.RegistryDelete(testDroneUser, "repository-1", "quay.io")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRegistryDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", config),
+			},
+			{
+				PreConfig:   conflict,
+				Config:      testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				ExpectError: regexp.MustCompile("Registry already exists"),
+			},
+			{
+				// the registries of the failed apply are read and applied again.
+				PreConfig: resolved,
+				Config:    testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_registry.registry",
+						"registries.#",
+						"3",
+					),
+					testRegistryContents("repository-1", map[string]string{
+						"docker.io": "user",
+						"gcr.io":    "_json_key",
+						"quay.io":   "robot",
+					}),
+				),
+			},
+			{
+				Config:                  testRegistryConfigDockerConfig(server, testDroneUser, "repository-1", updated),
+				ResourceName:            "drone_registry.registry",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"docker_config_json"},
+			},
+		},
+	})
+}
+
+// testRegistryServer starts a Drone 0.8 server, Drone 1.x has no registries.
+func testRegistryServer() *testServer {
+	server := newTestServer(testDroneUser, testServerToken)
+	server.version = "0.8.6"
+
+	return server
+}
+
+func testRegistryContents(repo string, usernames map[string]string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		client := testProvider.Meta().(*providerConfig).client
+
+		registries, err := client.RegistryList(testDroneUser, repo)
+
+		if err != nil {
+			return err
+		}
+
+		addresses := []string{}
+		expected := []string{}
+
+		for _, registry := range registries {
+			addresses = append(addresses, registry.Address)
+
+			if usernames[registry.Address] != registry.Username {
+				return fmt.Errorf("Expected registry %s username %s, got %s", registry.Address, usernames[registry.Address], registry.Username)
+			}
+		}
+
+		for address := range usernames {
+			expected = append(expected, address)
+		}
+
+		sort.Strings(addresses)
+		sort.Strings(expected)
+
+		if strings.Join(addresses, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("Expected registries %v, got %v", expected, addresses)
+		}
+
+		return nil
+	}
+}
+
 func testRegistryDestroy(state *terraform.State) error {
-	client := testProvider.Meta().(drone.Client)
+	client := testProvider.Meta().(*providerConfig).client
 
 	for _, resource := range state.RootModule().Resources {
 		if resource.Type != "drone_registry" {
@@ -84,15 +351,16 @@ func testRegistryDestroy(state *terraform.State) error {
 			return err
 		}
 
-		err = client.RegistryDelete(owner, repo, resource.Primary.Attributes["address"])
+		count, _ := strconv.Atoi(resource.Primary.Attributes["registries.#"])
+
+		for i := 0; i < count; i++ {
+			address := resource.Primary.Attributes[fmt.Sprintf("registries.%d", i)]
+
+			err = client.RegistryDelete(owner, repo, address)
 
-		if err == nil {
-			return fmt.Errorf(
-				"Registry still exists: %s/%s:%s",
-				owner,
-				repo,
-				resource.Primary.Attributes["address"],
-			)
+			if err == nil {
+				return fmt.Errorf("Registry still exists: %s/%s:%s", owner, repo, address)
+			}
 		}
 	}
 
@@ -3,29 +3,41 @@ package drone
 import (
 	"fmt"
 	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/terraform/helper/validation"
-	"regexp"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"strconv"
+	"strings"
 )
 
-var validRepoHooks = []string{
-	drone.EventPull,
-	drone.EventPush,
-	drone.EventTag,
-	drone.EventDeploy,
-}
+var (
+	validRepoHooks = []string{
+		drone.EventPull,
+		drone.EventPush,
+		drone.EventTag,
+		drone.EventDeploy,
+	}
+	repoCapabilities = map[string]capability{
+		"gated":                     capabilityGated,
+		"hooks":                     capabilityRepoHooks,
+		"protected":                 capabilityRepoSettings,
+		"ignore_forks":              capabilityRepoSettings,
+		"ignore_pull_requests":      capabilityRepoSettings,
+		"auto_cancel_pull_requests": capabilityRepoSettings,
+		"auto_cancel_pushes":        capabilityRepoSettings,
+		"throttle":                  capabilityRepoSettings,
+		"counter":                   capabilityRepoSettings,
+	}
+)
 
 func resourceRepo() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"repository": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-				ValidateFunc: validation.StringMatch(
-					regexp.MustCompile("^[^/ ]+/[^/ ]+$"),
-					"Invalid repository (e.g. octocat/hello-world)",
-				),
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
 			},
 			"trusted": {
 				Type:     schema.TypeBool,
@@ -44,6 +56,71 @@ func resourceRepo() *schema.Resource {
 				Optional: true,
 				Default:  "private",
 			},
+			"config_path": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validateConfigPath,
+			},
+			"protected": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"ignore_forks": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"ignore_pull_requests": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"auto_cancel_pull_requests": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"auto_cancel_pushes": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"throttle": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"counter": {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressCounterDiff,
+			},
+			"uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"slug": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"scm": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"http_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ssh_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"default_branch": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"signer": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
 			"hooks": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -59,6 +136,8 @@ func resourceRepo() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceRepoCustomizeDiff,
+
 		Create: resourceRepoCreate,
 		Read:   resourceRepoRead,
 		Update: resourceRepoUpdate,
@@ -68,9 +147,10 @@ func resourceRepo() *schema.Resource {
 }
 
 func resourceRepoCreate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
 
 	if err != nil {
 		return err
@@ -82,7 +162,7 @@ func resourceRepoCreate(data *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	repository, err := client.RepoPatch(owner, repo, createRepo(data))
+	repository, err := client.RepoUpdate(owner, repo, createRepo(client, data))
 
 	if err != nil {
 		return err
@@ -92,7 +172,7 @@ func resourceRepoCreate(data *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceRepoRead(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, err := parseRepo(data.Id())
 
@@ -100,27 +180,33 @@ func resourceRepoRead(data *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	repository, err := client.Repo(owner, repo)
+	repository, err := client.RepoInfo(owner, repo)
+
+	if isNotFound(err) || (err == nil && !repository.active()) {
+		data.SetId("")
+		return nil
+	}
 
 	return readRepo(data, repository, err)
 }
 
 func resourceRepoUpdate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
 
 	if err != nil {
 		return err
 	}
 
-	repository, err := client.RepoPatch(owner, repo, createRepo(data))
+	repository, err := client.RepoUpdate(owner, repo, createRepo(client, data))
 
 	return readRepo(data, repository, err)
 }
 
 func resourceRepoDelete(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, err := parseRepo(data.Id())
 
@@ -132,7 +218,7 @@ func resourceRepoDelete(data *schema.ResourceData, meta interface{}) error {
 }
 
 func resourceRepoExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, err := parseRepo(data.Id())
 
@@ -140,14 +226,28 @@ func resourceRepoExists(data *schema.ResourceData, meta interface{}) (bool, erro
 		return false, err
 	}
 
-	repository, err := client.Repo(owner, repo)
+	repository, err := client.RepoInfo(owner, repo)
 
-	exists := (repository.Owner == owner) && (repository.Name == repo) && (err == nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return (repository.Owner == owner) && (repository.Name == repo) && repository.active(), nil
+}
+
+func resourceRepoCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeRepositoryDiff(diff, meta); err != nil {
+		return err
+	}
 
-	return exists, err
+	return checkCapabilities(meta.(*providerConfig).client, diff, repoCapabilities)
 }
 
-func createRepo(data *schema.ResourceData) (repository *drone.RepoPatch) {
+func createRepo(client *apiClient, data *schema.ResourceData) (repository *repoPatch) {
 	hooks := data.Get("hooks").(*schema.Set)
 
 	trusted := data.Get("trusted").(bool)
@@ -158,22 +258,71 @@ func createRepo(data *schema.ResourceData) (repository *drone.RepoPatch) {
 	push := hooks.Contains(drone.EventPush)
 	deploy := hooks.Contains(drone.EventDeploy)
 	tag := hooks.Contains(drone.EventTag)
+	protected := data.Get("protected").(bool)
+	ignoreForks := data.Get("ignore_forks").(bool)
+	ignorePulls := data.Get("ignore_pull_requests").(bool)
+	cancelPulls := data.Get("auto_cancel_pull_requests").(bool)
+	cancelPush := data.Get("auto_cancel_pushes").(bool)
+	throttle := int64(data.Get("throttle").(int))
+
+	repository = &repoPatch{
+		RepoPatch: drone.RepoPatch{
+			IsTrusted:   &trusted,
+			IsGated:     &gated,
+			Timeout:     &timeout,
+			Visibility:  &visibility,
+			AllowPull:   &pull,
+			AllowPush:   &push,
+			AllowDeploy: &deploy,
+			AllowTag:    &tag,
+		},
+		Protected:   &protected,
+		IgnoreForks: &ignoreForks,
+		IgnorePulls: &ignorePulls,
+		CancelPulls: &cancelPulls,
+		CancelPush:  &cancelPush,
+		Throttle:    &throttle,
+	}
+
+	// the settings the server does not provide are left out, newer servers
+	// reject some of the settings of older ones.
+	if !client.supports(capabilityGated) {
+		repository.IsGated = nil
+	}
+
+	if !client.supports(capabilityRepoHooks) {
+		repository.AllowPull = nil
+		repository.AllowPush = nil
+		repository.AllowDeploy = nil
+		repository.AllowTag = nil
+	}
+
+	if !client.supports(capabilityRepoSettings) {
+		repository.Protected = nil
+		repository.IgnoreForks = nil
+		repository.IgnorePulls = nil
+		repository.CancelPulls = nil
+		repository.CancelPush = nil
+		repository.Throttle = nil
+	}
+
+	if v, ok := data.GetOk("config_path"); ok {
+		config := v.(string)
+
+		repository.Config = &config
+		repository.ConfigPath = &config
+	}
+
+	if v, ok := data.GetOk("counter"); ok && data.HasChange("counter") && client.supports(capabilityRepoSettings) {
+		counter := int64(v.(int))
 
-	repository = &drone.RepoPatch{
-		IsTrusted:   &trusted,
-		IsGated:     &gated,
-		Timeout:     &timeout,
-		Visibility:  &visibility,
-		AllowPull:   &pull,
-		AllowPush:   &push,
-		AllowDeploy: &deploy,
-		AllowTag:    &tag,
+		repository.Counter = &counter
 	}
 
 	return
 }
 
-func readRepo(data *schema.ResourceData, repository *drone.Repo, err error) error {
+func readRepo(data *schema.ResourceData, repository *repoInfo, err error) error {
 	if err != nil {
 		return err
 	}
@@ -204,6 +353,59 @@ func readRepo(data *schema.ResourceData, repository *drone.Repo, err error) erro
 	data.Set("timeout", repository.Timeout)
 	data.Set("visibility", repository.Visibility)
 	data.Set("hooks", hooks)
+	data.Set("config_path", repository.ConfigPath)
+	data.Set("protected", repository.Protected)
+	data.Set("ignore_forks", repository.IgnoreForks)
+	data.Set("ignore_pull_requests", repository.IgnorePulls)
+	data.Set("auto_cancel_pull_requests", repository.CancelPulls)
+	data.Set("auto_cancel_pushes", repository.CancelPush)
+	data.Set("throttle", repository.Throttle)
+	data.Set("counter", repository.Counter)
+	data.Set("uid", repository.UID)
+	data.Set("slug", repository.Slug)
+	data.Set("scm", repository.Kind)
+	data.Set("http_url", repository.HTTPURL)
+	data.Set("ssh_url", repository.SSHURL)
+	data.Set("default_branch", repository.Branch)
+	data.Set("signer", repository.Signer)
 
 	return nil
 }
+
+// suppressCounterDiff ignores a configured build counter that the server has
+// already passed, builds advance the counter but it can only be moved forward.
+func suppressCounterDiff(k, old, new string, data *schema.ResourceData) bool {
+	current, err := strconv.ParseInt(old, 10, 64)
+
+	if err != nil {
+		return false
+	}
+
+	desired, err := strconv.ParseInt(new, 10, 64)
+
+	if err != nil {
+		return false
+	}
+
+	return desired <= current
+}
+
+// validateConfigPath checks that the pipeline configuration is a file
+// relative to the repository root.
+func validateConfigPath(v interface{}, k string) (ws []string, es []error) {
+	value := v.(string)
+
+	if value == "" || strings.HasPrefix(value, "/") || strings.HasSuffix(value, "/") {
+		es = append(es, fmt.Errorf("%s: Invalid path %q, expected a file relative to the repository root (e.g. .drone.yml)", k, value))
+		return
+	}
+
+	for _, segment := range strings.Split(value, "/") {
+		if segment == ".." {
+			es = append(es, fmt.Errorf("%s: Invalid path %q, the file must be inside the repository", k, value))
+			return
+		}
+	}
+
+	return
+}
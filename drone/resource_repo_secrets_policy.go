@@ -0,0 +1,334 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"path"
+	"sort"
+)
+
+func resourceRepoSecretsPolicy() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
+			},
+			"secrets": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"registries": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"allowed_secrets": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateGlob,
+				},
+			},
+			"allowed_registries": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateGlob,
+				},
+			},
+			"unmanaged_secrets": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"unmanaged_registries": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: resourceRepoSecretsPolicyCustomizeDiff,
+
+		Create: resourceRepoSecretsPolicyCreate,
+		Read:   resourceRepoSecretsPolicyRead,
+		Update: resourceRepoSecretsPolicyUpdate,
+		Delete: resourceRepoSecretsPolicyDelete,
+	}
+}
+
+func resourceRepoSecretsPolicyCreate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s", owner, repo))
+
+	return enforceSecretsPolicy(data, client, owner, repo)
+}
+
+func resourceRepoSecretsPolicyRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	policy := newSecretsPolicy(data.Get)
+
+	secrets, err := client.SecretList(owner, repo)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	registries, err := listRegistries(client, owner, repo)
+
+	if err != nil {
+		return err
+	}
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("unmanaged_secrets", policy.prunedSecrets(secrets))
+	data.Set("unmanaged_registries", policy.prunedRegistries(registries))
+
+	return nil
+}
+
+func resourceRepoSecretsPolicyUpdate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	return enforceSecretsPolicy(data, client, owner, repo)
+}
+
+func resourceRepoSecretsPolicyDelete(data *schema.ResourceData, meta interface{}) error {
+	// removing the policy stops pruning, the secrets are left as they are.
+	return nil
+}
+
+func resourceRepoSecretsPolicyCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeRepositoryDiff(diff, meta); err != nil {
+		return err
+	}
+
+	config := meta.(*providerConfig)
+	client := config.client
+
+	if diff.Id() == "" || !diff.NewValueKnown("repository") {
+		return nil
+	}
+
+	owner, repo, err := config.parseRepo(diff.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	policy := newSecretsPolicy(diff.Get)
+
+	secrets, err := client.SecretList(owner, repo)
+
+	// a repository removed outside of terraform is dropped on refresh.
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := planPruned(diff, "unmanaged_secrets", policy.prunedSecrets(secrets)); err != nil {
+		return err
+	}
+
+	registries, err := listRegistries(client, owner, repo)
+
+	if err != nil {
+		return err
+	}
+
+	return planPruned(diff, "unmanaged_registries", policy.prunedRegistries(registries))
+}
+
+// planPruned plans the deletion of the unmanaged items held in key. Items that
+// were refreshed show up in the plan, items only unmanaged by a change to the
+// configuration are known after apply.
+func planPruned(diff *schema.ResourceDiff, key string, pruned []string) error {
+	known := diff.Get(key).(*schema.Set)
+
+	for _, item := range pruned {
+		if !known.Contains(item) {
+			return diff.SetNewComputed(key)
+		}
+	}
+
+	return diff.SetNew(key, []string{})
+}
+
+// enforceSecretsPolicy deletes every secret and registry of the repository
+// that is neither declared nor allowed.
+func enforceSecretsPolicy(data *schema.ResourceData, client *apiClient, owner, repo string) error {
+	policy := newSecretsPolicy(data.Get)
+
+	secrets, err := client.SecretList(owner, repo)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range policy.prunedSecrets(secrets) {
+		if err := client.SecretInfoDelete(owner, repo, name); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	registries, err := listRegistries(client, owner, repo)
+
+	if err != nil {
+		return err
+	}
+
+	for _, address := range policy.prunedRegistries(registries) {
+		if err := client.RegistryDelete(owner, repo, address); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("unmanaged_secrets", []string{})
+	data.Set("unmanaged_registries", []string{})
+
+	return nil
+}
+
+// listRegistries returns the registries of the repository, none when the
+// server does not provide them.
+func listRegistries(client *apiClient, owner, repo string) ([]*drone.Registry, error) {
+	if !client.supports(capabilityRepoRegistries) {
+		return nil, nil
+	}
+
+	return client.RegistryList(owner, repo)
+}
+
+// secretsPolicy decides which secrets and registries of a repository are
+// pruned.
+type secretsPolicy struct {
+	secrets           map[string]bool
+	registries        map[string]bool
+	allowedSecrets    []string
+	allowedRegistries []string
+}
+
+func newSecretsPolicy(get func(string) interface{}) *secretsPolicy {
+	policy := &secretsPolicy{
+		secrets:    map[string]bool{},
+		registries: map[string]bool{},
+	}
+
+	for _, v := range get("secrets").(*schema.Set).List() {
+		policy.secrets[v.(string)] = true
+	}
+
+	for _, v := range get("registries").(*schema.Set).List() {
+		policy.registries[normalizeRegistryAddress(v.(string))] = true
+	}
+
+	for _, v := range get("allowed_secrets").([]interface{}) {
+		policy.allowedSecrets = append(policy.allowedSecrets, v.(string))
+	}
+
+	for _, v := range get("allowed_registries").([]interface{}) {
+		policy.allowedRegistries = append(policy.allowedRegistries, v.(string))
+	}
+
+	return policy
+}
+
+func (p *secretsPolicy) prunedSecrets(secrets []*drone.Secret) []string {
+	pruned := []string{}
+
+	for _, secret := range secrets {
+		if !p.secrets[secret.Name] && !matchGlobs(p.allowedSecrets, secret.Name) {
+			pruned = append(pruned, secret.Name)
+		}
+	}
+
+	sort.Strings(pruned)
+
+	return pruned
+}
+
+func (p *secretsPolicy) prunedRegistries(registries []*drone.Registry) []string {
+	pruned := []string{}
+
+	for _, registry := range registries {
+		// declared and stored addresses are compared in their normalized
+		// form, the stored one is kept for the deletion.
+		address := normalizeRegistryAddress(registry.Address)
+
+		if !p.registries[address] && !matchGlobs(p.allowedRegistries, registry.Address) && !matchGlobs(p.allowedRegistries, address) {
+			pruned = append(pruned, registry.Address)
+		}
+	}
+
+	sort.Strings(pruned)
+
+	return pruned
+}
+
+func matchGlobs(patterns []string, str string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, str); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateGlob(v interface{}, k string) (ws []string, es []error) {
+	if _, err := path.Match(v.(string), ""); err != nil {
+		es = append(es, fmt.Errorf("%s: Invalid pattern %q: %s", k, v, err))
+	}
+
+	return
+}
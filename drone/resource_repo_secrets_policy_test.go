@@ -0,0 +1,229 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testRepoSecretsPolicyConfigBasic(server *testServer, user, repo string) string {
+	registry := ""
+	address := "example.com"
+
+	// only Drone 0.8 has registries.
+	if server.legacy() {
+		registry = `
+    resource "drone_registry" "registry" {
+      repository = "${drone_repo.repo.repository}"
+      address    = "example.com"
+      username   = "user"
+      password   = "pass"
+    }
+    `
+		address = "${drone_registry.registry.address}"
+	}
+
+	return testProviderConfig(server) + fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secret" "secret" {
+      repository = "${drone_repo.repo.repository}"
+      name       = "password"
+      value      = "1234567890"
+    }
+    %s
+    resource "drone_repo_secrets_policy" "policy" {
+      repository      = "${drone_repo.repo.repository}"
+      secrets         = ["${drone_secret.secret.name}"]
+      registries      = ["%s"]
+      allowed_secrets = ["shared_*"]
+    }
+    `,
+		user,
+		repo,
+		registry,
+		address,
+	)
+}
+
+func TestRepoSecretsPolicy(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+		registries    []string
+	}{
+		{"Test Drone 1.x", "1.10.1", []string{}},
+		{"Test Drone 0.8", "0.8.6", []string{"example.com"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(testDroneUser, testServerToken)
+			server.version = test.version
+			defer server.Close()
+
+			unmanaged := func() {
+				client := testProvider.Meta().(*providerConfig).client
+
+				client.SecretCreate(testDroneUser, "repository-1", &drone.Secret{
+					Name:   "leaked",
+					Value:  "leaked",
+					Events: defaultSecretEvents,
+				})
+				client.SecretCreate(testDroneUser, "repository-1", &drone.Secret{
+					Name:   "shared_token",
+					Value:  "shared",
+					Events: defaultSecretEvents,
+				})
+
+				if server.legacy() {
+					client.RegistryCreate(testDroneUser, "repository-1", &drone.Registry{
+						Address:  "evil.example.com",
+						Username: "user",
+						Password: "pass",
+					})
+				}
+			}
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:     func() { testAccPreCheck(t) },
+				Providers:    testProviders,
+				CheckDestroy: testSecretDestroy,
+				Steps: []resource.TestStep{
+					{
+						Config: testRepoSecretsPolicyConfigBasic(server, testDroneUser, "repository-1"),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr(
+								"drone_repo_secrets_policy.policy",
+								"unmanaged_secrets.#",
+								"0",
+							),
+							testRepoSecretsPolicyContents(
+								"repository-1",
+								[]string{"password"},
+								test.registries,
+							),
+						),
+					},
+					{
+						// secrets added outside of terraform are planned for deletion.
+						PreConfig:          unmanaged,
+						Config:             testRepoSecretsPolicyConfigBasic(server, testDroneUser, "repository-1"),
+						PlanOnly:           true,
+						ExpectNonEmptyPlan: true,
+					},
+					{
+						Config: testRepoSecretsPolicyConfigBasic(server, testDroneUser, "repository-1"),
+						Check: resource.ComposeTestCheckFunc(
+							resource.TestCheckResourceAttr(
+								"drone_repo_secrets_policy.policy",
+								"unmanaged_secrets.#",
+								"0",
+							),
+							resource.TestCheckResourceAttr(
+								"drone_repo_secrets_policy.policy",
+								"unmanaged_registries.#",
+								"0",
+							),
+							testRepoSecretsPolicyContents(
+								"repository-1",
+								[]string{"password", "shared_token"},
+								test.registries,
+							),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func testRepoSecretsPolicyContents(repo string, secrets, registries []string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		client := testProvider.Meta().(*providerConfig).client
+
+		secretList, err := client.SecretList(testDroneUser, repo)
+
+		if err != nil {
+			return err
+		}
+
+		names := []string{}
+
+		for _, secret := range secretList {
+			names = append(names, secret.Name)
+		}
+
+		sort.Strings(names)
+
+		if strings.Join(names, ",") != strings.Join(secrets, ",") {
+			return fmt.Errorf("Expected secrets %v, got %v", secrets, names)
+		}
+
+		registryList, err := listRegistries(client, testDroneUser, repo)
+
+		if err != nil {
+			return err
+		}
+
+		addresses := []string{}
+
+		for _, registry := range registryList {
+			addresses = append(addresses, registry.Address)
+		}
+
+		sort.Strings(addresses)
+
+		if strings.Join(addresses, ",") != strings.Join(registries, ",") {
+			return fmt.Errorf("Expected registries %v, got %v", registries, addresses)
+		}
+
+		return nil
+	}
+}
+
+func TestSecretsPolicyPrunedRegistries(t *testing.T) {
+	testCases := []struct {
+		name       string
+		registries []interface{}
+		allowed    []interface{}
+		stored     []string
+		pruned     []string
+	}{
+		{"Test declared address", []interface{}{"example.com"}, []interface{}{}, []string{"example.com", "evil.example.com"}, []string{"evil.example.com"}},
+		{"Test declared raw address", []interface{}{"https://index.docker.io/v1/"}, []interface{}{}, []string{"docker.io"}, []string{}},
+		{"Test stored raw address", []interface{}{"docker.io"}, []interface{}{}, []string{"https://index.docker.io/v1/"}, []string{}},
+		{"Test allowed glob", []interface{}{}, []interface{}{"*.example.com"}, []string{"example.com", "gcr.example.com"}, []string{"example.com"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attributes := map[string]interface{}{
+				"secrets":            schema.NewSet(schema.HashString, []interface{}{}),
+				"registries":         schema.NewSet(schema.HashString, tc.registries),
+				"allowed_secrets":    []interface{}{},
+				"allowed_registries": tc.allowed,
+			}
+
+			policy := newSecretsPolicy(func(key string) interface{} {
+				return attributes[key]
+			})
+
+			registries := []*drone.Registry{}
+
+			for _, address := range tc.stored {
+				registries = append(registries, &drone.Registry{Address: address})
+			}
+
+			pruned := policy.prunedRegistries(registries)
+
+			if strings.Join(pruned, ",") != strings.Join(tc.pruned, ",") {
+				t.Errorf("Expected pruned registries %v, got %v", tc.pruned, pruned)
+			}
+		})
+	}
+}
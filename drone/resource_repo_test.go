@@ -2,17 +2,71 @@ package drone
 
 import (
 	"fmt"
-	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"regexp"
 	"testing"
 )
 
 func testRepoConfigBasic(user, repo string) string {
 	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+    `, user, repo)
+}
+
+func testRepoConfigDefaultNamespace(namespace, repo string) string {
+	return fmt.Sprintf(`
+    provider "drone" {
+      default_namespace = "%s"
+    }
+
+    resource "drone_repo" "repo" {
+      repository = "%s"
+    }
+    `, namespace, repo)
+}
+
+func testRepoConfigWithoutNamespace(repo string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s"
+    }
+    `, repo)
+}
+
+func testRepoConfigLegacy(server *testServer, user, repo string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
     resource "drone_repo" "repo" {
       repository = "%s/%s"
       hooks      = ["push", "pull_request", "tag", "deployment"]
+      gated      = true
+    }
+    `, user, repo)
+}
+
+func testRepoConfigAttribute(server *testServer, user, repo, attribute, value string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+      %s = %s
+    }
+    `, user, repo, attribute, value)
+}
+
+func testRepoConfigSettings(user, repo string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository                = "%s/%s"
+      config_path               = ".drone.jsonnet"
+      protected                 = true
+      ignore_forks              = true
+      ignore_pull_requests      = true
+      auto_cancel_pull_requests = true
+      auto_cancel_pushes        = true
+      throttle                  = 2
+      counter                   = 100
     }
     `, user, repo)
 }
@@ -36,6 +90,168 @@ func TestRepo(t *testing.T) {
 						"visibility",
 						"private",
 					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"hooks.#",
+						"0",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"timeout",
+						"0",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"timeout",
+						"0",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"gated",
+						"false",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"trusted",
+						"false",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_repo.repo",
+						"uid",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"slug",
+						fmt.Sprintf("%s/repository-1", testDroneUser),
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_repo.repo",
+						"scm",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_repo.repo",
+						"http_url",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_repo.repo",
+						"default_branch",
+					),
+				),
+			},
+			{
+				Config: testRepoConfigSettings(testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"config_path",
+						".drone.jsonnet",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"protected",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"ignore_forks",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"ignore_pull_requests",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"auto_cancel_pull_requests",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"auto_cancel_pushes",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"throttle",
+						"2",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"counter",
+						"100",
+					),
+				),
+			},
+			{
+				ResourceName:      "drone_repo.repo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestRepoDefaultNamespace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRepoDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRepoConfigDefaultNamespace(testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_repo.repo",
+						"repository",
+						fmt.Sprintf("%s/repository-1", testDroneUser),
+					),
+				),
+			},
+			{
+				ResourceName:      "drone_repo.repo",
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s/repository-1", testDroneUser),
+				ImportStateVerify: true,
+			},
+			{
+				// another default namespace replaces the repository.
+				Config:             testRepoConfigDefaultNamespace("octo-org", "repository-1"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestRepoWithoutNamespace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRepoDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testRepoConfigWithoutNamespace("repository-1"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("set the provider default_namespace"),
+			},
+		},
+	})
+}
+
+func TestRepoLegacy(t *testing.T) {
+	server := newTestServer(testDroneUser, testServerToken)
+	server.version = "0.8.6"
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRepoDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testRepoConfigLegacy(server, testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(
 						"drone_repo.repo",
 						"hooks.#",
@@ -61,34 +277,44 @@ func TestRepo(t *testing.T) {
 						"hooks.696883710",
 						"push",
 					),
-					resource.TestCheckResourceAttr(
-						"drone_repo.repo",
-						"timeout",
-						"0",
-					),
-					resource.TestCheckResourceAttr(
-						"drone_repo.repo",
-						"timeout",
-						"0",
-					),
 					resource.TestCheckResourceAttr(
 						"drone_repo.repo",
 						"gated",
-						"false",
-					),
-					resource.TestCheckResourceAttr(
-						"drone_repo.repo",
-						"trusted",
-						"false",
+						"true",
 					),
 				),
 			},
+			{
+				Config:      testRepoConfigAttribute(server, testDroneUser, "repository-1", "protected", "true"),
+				ExpectError: regexp.MustCompile("`protected` is not supported by Drone 0.8"),
+			},
+		},
+	})
+}
+
+func TestRepoCapabilities(t *testing.T) {
+	server := newTestServer(testDroneUser, testServerToken)
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testRepoDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testRepoConfigAttribute(server, testDroneUser, "repository-1", "gated", "true"),
+				ExpectError: regexp.MustCompile("`gated` is not supported by Drone 1.x"),
+			},
+			{
+				Config:      testRepoConfigAttribute(server, testDroneUser, "repository-1", "hooks", `["push"]`),
+				ExpectError: regexp.MustCompile("`hooks` is not supported by Drone 1.x"),
+			},
 		},
 	})
 }
 
 func testRepoDestroy(state *terraform.State) error {
-	client := testProvider.Meta().(drone.Client)
+	client := testProvider.Meta().(*providerConfig).client
 
 	for _, resource := range state.RootModule().Resources {
 		if resource.Type != "drone_repo" {
@@ -113,3 +339,29 @@ func testRepoDestroy(state *terraform.State) error {
 
 	return nil
 }
+
+func TestValidateConfigPath(t *testing.T) {
+	for _, test := range []struct {
+		name, path string
+		is_error   bool
+	}{
+		{"Test default path", ".drone.yml", false},
+		{"Test nested path", "ci/pipeline.jsonnet", false},
+		{"Test empty path", "", true},
+		{"Test absolute path", "/etc/drone.yml", true},
+		{"Test directory", "ci/", true},
+		{"Test path outside the repository", "ci/../../drone.yml", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, errs := validateConfigPath(test.path, "config_path")
+
+			if (test.is_error == true) && (len(errs) == 0) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (len(errs) != 0) {
+				t.Errorf("unexpected error")
+			}
+		})
+	}
+}
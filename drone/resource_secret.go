@@ -1,13 +1,19 @@
 package drone
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/schema"
-	"github.com/hashicorp/terraform/helper/validation"
-	"regexp"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"io/ioutil"
+	"os"
 )
 
+// maxSecretSize is the largest secret value that is accepted, larger values
+// do not fit the server's database column.
+const maxSecretSize = 64 * 1024
+
 var (
 	defaultSecretEvents = []string{
 		drone.EventPush,
@@ -20,19 +26,23 @@ var (
 		drone.EventTag,
 		drone.EventDeploy,
 	}
+	secretCapabilities = map[string]capability{
+		"events":                     capabilitySecretFilters,
+		"images":                     capabilitySecretFilters,
+		"allow_pull_request":         capabilitySecretPullRequest,
+		"allow_push_on_pull_request": capabilitySecretPullRequest,
+	}
 )
 
 func resourceSecret() *schema.Resource {
 	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"repository": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-				ValidateFunc: validation.StringMatch(
-					regexp.MustCompile("^[^/ ]+/[^/ ]+$"),
-					"Invalid repository (e.g. octocat/hello-world)",
-				),
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
 			},
 			"name": {
 				Type:     schema.TypeString,
@@ -40,9 +50,28 @@ func resourceSecret() *schema.Resource {
 				ForceNew: true,
 			},
 			"value": {
-				Type:      schema.TypeString,
-				Required:  true,
-				Sensitive: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"value_file", "value_base64"},
+				DiffSuppressFunc: suppressSecretDiff("value_version"),
+			},
+			"value_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"value", "value_base64"},
+			},
+			"value_base64": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"value", "value_file"},
+				DiffSuppressFunc: suppressSecretDiff("value_version"),
+			},
+			"value_version": {
+				Type:     schema.TypeString,
+				Optional: true,
 			},
 			"images": {
 				Type:     schema.TypeSet,
@@ -55,18 +84,45 @@ func resourceSecret() *schema.Resource {
 			"events": {
 				Type:     schema.TypeSet,
 				Optional: true,
+				Computed: true,
 				// ValidateFunc: validation.ValidateListUniqueStrings,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
 					ValidateFunc: validation.StringInSlice(validSecretEvents, true),
 				},
 			},
+			"allow_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"allow_push_on_pull_request": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 		},
 
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceSecretV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeSecretHashState("value"),
+			},
+			{
+				Version: 1,
+				Type:    resourceSecretV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: upgradeIdState(""),
+			},
+		},
+
+		CustomizeDiff: resourceSecretCustomizeDiff,
+
 		Create: resourceSecretCreate,
 		Read:   resourceSecretRead,
 		Update: resourceSecretUpdate,
@@ -76,23 +132,38 @@ func resourceSecret() *schema.Resource {
 }
 
 func resourceSecretCreate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	secret, err := createSecret(client, data)
 
 	if err != nil {
 		return err
 	}
 
-	secret, err := client.SecretCreate(owner, repo, createSecret(data))
+	value := secret.Value
 
-	data.Set("value", data.Get("value").(string))
+	secret, err = client.SecretInfoCreate(owner, repo, secret)
 
-	return readSecret(data, owner, repo, secret, err)
+	if err != nil {
+		return err
+	}
+
+	if err := setSecretValueHash(data, value); err != nil {
+		return err
+	}
+
+	return readSecret(client, data, owner, repo, secret, err)
 }
 
 func resourceSecretRead(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, name, err := parseId(data.Id(), "secret_password")
 
@@ -100,29 +171,51 @@ func resourceSecretRead(data *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	secret, err := client.Secret(owner, repo, name)
+	secret, err := client.SecretInfo(owner, repo, name)
 
-	return readSecret(data, owner, repo, secret, err)
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readSecret(client, data, owner, repo, secret, err)
 }
 
 func resourceSecretUpdate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
 
-	owner, repo, err := parseRepo(data.Get("repository").(string))
+	secret, err := createSecret(client, data)
 
 	if err != nil {
 		return err
 	}
 
-	secret, err := client.SecretUpdate(owner, repo, createSecret(data))
+	value := secret.Value
+
+	secret, err = client.SecretInfoUpdate(owner, repo, secret)
+
+	if err != nil {
+		return err
+	}
 
-	data.Set("value", data.Get("value").(string))
+	if value != "" {
+		if err := setSecretValueHash(data, value); err != nil {
+			return err
+		}
+	}
 
-	return readSecret(data, owner, repo, secret, err)
+	return readSecret(client, data, owner, repo, secret, err)
 }
 
 func resourceSecretDelete(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, name, err := parseId(data.Id(), "secret_password")
 
@@ -130,11 +223,11 @@ func resourceSecretDelete(data *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	return client.SecretDelete(owner, repo, name)
+	return client.SecretInfoDelete(owner, repo, name)
 }
 
 func resourceSecretExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	owner, repo, name, err := parseId(data.Id(), "secret_password")
 
@@ -142,14 +235,60 @@ func resourceSecretExists(data *schema.ResourceData, meta interface{}) (bool, er
 		return false, err
 	}
 
-	secret, err := client.Secret(owner, repo, name)
+	secret, err := client.SecretInfo(owner, repo, name)
 
-	exists := (secret.Name == name) && (err == nil)
+	if isNotFound(err) {
+		return false, nil
+	}
 
-	return exists, err
+	if err != nil {
+		return false, err
+	}
+
+	return secret.Name == name, nil
 }
 
-func createSecret(data *schema.ResourceData) (secret *drone.Secret) {
+func resourceSecretCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeRepositoryDiff(diff, meta); err != nil {
+		return err
+	}
+
+	// the server ignores the attributes of the other api version.
+	warnCapabilities(meta.(*providerConfig).client, diff, secretCapabilities)
+
+	// the content of a value file is not part of the configuration, it is
+	// compared against the hash in state to find changes. A new secret always
+	// writes its value.
+	if diff.Id() == "" {
+		return nil
+	}
+
+	if !diff.NewValueKnown("value_file") {
+		return diff.SetNewComputed("value")
+	}
+
+	path, ok := diff.GetOk("value_file")
+
+	if !ok {
+		return nil
+	}
+
+	value, err := readSecretFile(path.(string))
+
+	if err != nil {
+		return err
+	}
+
+	old, _ := diff.GetChange("value")
+
+	if diff.HasChange("value_version") || !secretHashMatches(old.(string), value) {
+		return diff.SetNewComputed("value")
+	}
+
+	return nil
+}
+
+func createSecret(client *apiClient, data *schema.ResourceData) (secret *secretInfo, err error) {
 	events := []string{}
 	eventSet := data.Get("events").(*schema.Set)
 	for _, v := range eventSet.List() {
@@ -162,31 +301,146 @@ func createSecret(data *schema.ResourceData) (secret *drone.Secret) {
 		images = append(images, v.(string))
 	}
 
-	secret = &drone.Secret{
-		Name:   data.Get("name").(string),
-		Value:  data.Get("value").(string),
-		Images: images,
-		Events: events,
+	value, err := secretValue(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" && data.IsNewResource() {
+		return nil, fmt.Errorf("Error: One of value, value_file or value_base64 is required.")
 	}
 
-	if len(secret.Events) == 0 {
+	if len(value) > maxSecretSize {
+		return nil, fmt.Errorf("Error: Secret value exceeds %d bytes.", maxSecretSize)
+	}
+
+	secret = &secretInfo{
+		Secret: drone.Secret{
+			Name:   data.Get("name").(string),
+			Value:  value,
+			Images: images,
+			Events: events,
+		},
+		PullRequest:     data.Get("allow_pull_request").(bool),
+		PullRequestPush: data.Get("allow_push_on_pull_request").(bool),
+	}
+
+	// Drone 1.x replaced the events and images of a secret with the pull
+	// request flags, they are not sent to servers that ignore them.
+	if !client.supports(capabilitySecretFilters) {
+		secret.Events = nil
+		secret.Images = nil
+	} else if len(secret.Events) == 0 {
 		secret.Events = defaultSecretEvents
 	}
 
 	return
 }
 
-func readSecret(data *schema.ResourceData, owner, repo string, secret *drone.Secret, err error) error {
+// secretValue returns the secret value to write, or an empty string when the
+// value kept by the server is current.
+func secretValue(data *schema.ResourceData) (string, error) {
+	write := data.IsNewResource() || data.HasChange("value_version")
+
+	if path, ok := data.GetOk("value_file"); ok {
+		value, err := readSecretFile(path.(string))
+
+		if err != nil {
+			return "", err
+		}
+
+		old, _ := data.GetChange("value")
+
+		if !write && secretHashMatches(old.(string), value) {
+			return "", nil
+		}
+
+		return value, nil
+	}
+
+	if encoded, ok := data.GetOk("value_base64"); ok {
+		if !write && !data.HasChange("value_base64") {
+			return "", nil
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded.(string))
+
+		if err != nil {
+			return "", fmt.Errorf("Error: Invalid value_base64: %s.", err)
+		}
+
+		return string(value), nil
+	}
+
+	if !write && !data.HasChange("value") {
+		return "", nil
+	}
+
+	return data.Get("value").(string), nil
+}
+
+func readSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() > maxSecretSize {
+		return "", fmt.Errorf("Error: Secret file %s exceeds %d bytes.", path, maxSecretSize)
+	}
+
+	value, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// setSecretValueHash replaces the written secret value in state with its
+// hash.
+func setSecretValueHash(data *schema.ResourceData, value string) error {
+	hash, err := newSecretHash(value)
+
 	if err != nil {
 		return err
 	}
 
-	data.SetId(fmt.Sprintf("%s/%s/%s", owner, repo, secret.Name))
+	if err := data.Set("value", hash); err != nil {
+		return err
+	}
+
+	if _, ok := data.GetOk("value_base64"); ok {
+		return setSecretHash(data, "value_base64")
+	}
+
+	return nil
+}
+
+func readSecret(client *apiClient, data *schema.ResourceData, owner, repo string, secret *secretInfo, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(formatId(owner, repo, secret.Name))
 
 	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
 	data.Set("name", secret.Name)
-	data.Set("images", secret.Images)
-	data.Set("events", secret.Events)
+
+	// the attributes the server ignores keep their configured value, they
+	// would otherwise plan a change on every refresh.
+	if client.supports(capabilitySecretFilters) {
+		data.Set("images", secret.Images)
+		data.Set("events", secret.Events)
+	}
+
+	if client.supports(capabilitySecretPullRequest) {
+		data.Set("allow_pull_request", secret.PullRequest)
+		data.Set("allow_push_on_pull_request", secret.PullRequestPush)
+	}
 
 	return nil
 }
@@ -1,10 +1,15 @@
 package drone
 
 import (
+	"encoding/base64"
 	"fmt"
-	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -18,7 +23,6 @@ func testSecretConfigBasic(user, repo, name, value string) string {
       repository = "${drone_repo.repo.repository}"
       name       = "%s"
       value      = "%s"
-      events     = ["push", "pull_request", "tag", "deployment"]
     }
     `,
 		user,
@@ -52,7 +56,7 @@ func TestSecret(t *testing.T) {
 						"name",
 						"password",
 					),
-					resource.TestCheckResourceAttr(
+					testCheckSecretHash(
 						"drone_secret.secret",
 						"value",
 						"1234567890",
@@ -62,6 +66,97 @@ func TestSecret(t *testing.T) {
 						"images.#",
 						"0",
 					),
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"events.#",
+						"0",
+					),
+				),
+			},
+			{
+				Config: testSecretConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"password",
+					"0987654321",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretHash(
+						"drone_secret.secret",
+						"value",
+						"0987654321",
+					),
+				),
+			},
+			{
+				// a secret deleted outside of terraform is recreated.
+				PreConfig: func() {
+					client := testProvider.Meta().(*providerConfig).client
+					client.SecretInfoDelete(testDroneUser, "repository-1", "password")
+				},
+				Config: testSecretConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"password",
+					"1234567890",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testSecretExists("drone_secret.secret"),
+				),
+			},
+		},
+	})
+}
+
+func testSecretConfigLegacy(server *testServer, user, repo string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secret" "secret" {
+      repository = "${drone_repo.repo.repository}"
+      name       = "password"
+      value      = "1234567890"
+      images     = ["plugins/docker"]
+      events     = ["push", "pull_request", "tag", "deployment"]
+    }
+    `, user, repo)
+}
+
+func testSecretConfigAttribute(server *testServer, user, repo, attribute, value string) string {
+	return testProviderConfig(server) + fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secret" "secret" {
+      repository = "${drone_repo.repo.repository}"
+      name       = "password"
+      value      = "1234567890"
+      %s = %s
+    }
+    `, user, repo, attribute, value)
+}
+
+func TestSecretLegacy(t *testing.T) {
+	server := newTestServer(testDroneUser, testServerToken)
+	server.version = "0.8.6"
+	defer server.Close()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretConfigLegacy(server, testDroneUser, "repository-1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"images.#",
+						"1",
+					),
 					resource.TestCheckResourceAttr(
 						"drone_secret.secret",
 						"events.#",
@@ -93,8 +188,369 @@ func TestSecret(t *testing.T) {
 	})
 }
 
+func TestSecretCapabilities(t *testing.T) {
+	for _, test := range []struct {
+		name, version, attribute, value string
+		key, expected                   string
+	}{
+		{"Test events on 1.x", "1.10.1", "events", `["push"]`, "events.#", "1"},
+		{"Test images on 1.x", "1.10.1", "images", `["plugins/docker"]`, "images.#", "1"},
+		{"Test pull request on 0.8", "0.8.6", "allow_pull_request", "true", "allow_pull_request", "true"},
+		{"Test push on pull request on 0.8", "0.8.6", "allow_push_on_pull_request", "true", "allow_push_on_pull_request", "true"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(testDroneUser, testServerToken)
+			server.version = test.version
+			defer server.Close()
+
+			// the attribute is ignored with a warning, it is kept in state
+			// and plans no change.
+			resource.Test(t, resource.TestCase{
+				PreCheck:     func() { testAccPreCheck(t) },
+				Providers:    testProviders,
+				CheckDestroy: testSecretDestroy,
+				Steps: []resource.TestStep{
+					{
+						Config: testSecretConfigAttribute(server, testDroneUser, "repository-1", test.attribute, test.value),
+						Check: resource.ComposeTestCheckFunc(
+							testSecretExists("drone_secret.secret"),
+							resource.TestCheckResourceAttr(
+								"drone_secret.secret",
+								test.key,
+								test.expected,
+							),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestSecretEscapedName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"deploy/key #1",
+					"1234567890",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"id",
+						fmt.Sprintf("%s/repository-1/deploy%%2Fkey%%20%%231", testDroneUser),
+					),
+					testSecretExists("drone_secret.secret"),
+				),
+			},
+			{
+				Config: testSecretConfigBasic(
+					testDroneUser,
+					"repository-1",
+					"deploy/key #1",
+					"0987654321",
+				),
+				Check: testCheckSecretHash(
+					"drone_secret.secret",
+					"value",
+					"0987654321",
+				),
+			},
+			{
+				ResourceName:            "drone_secret.secret",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"value"},
+			},
+		},
+	})
+}
+
+func testSecretConfigSource(user, repo, name, argument, value string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secret" "secret" {
+      repository = "${drone_repo.repo.repository}"
+      name       = "%s"
+      %s = "%s"
+    }
+    `,
+		user,
+		repo,
+		name,
+		argument,
+		value,
+	)
+}
+
+func TestSecretValueFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+
+	write := func(value string) func() {
+		return func() {
+			if err := ioutil.WriteFile(path, []byte(value), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	write("apiVersion: v1\nkind: Config\n")()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretConfigSource(
+					testDroneUser,
+					"repository-1",
+					"kubeconfig",
+					"value_file",
+					path,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"value_file",
+						path,
+					),
+					testCheckSecretHash(
+						"drone_secret.secret",
+						"value",
+						"apiVersion: v1\nkind: Config\n",
+					),
+				),
+			},
+			{
+				// a changed file is written again.
+				PreConfig: write("apiVersion: v2\n"),
+				Config: testSecretConfigSource(
+					testDroneUser,
+					"repository-1",
+					"kubeconfig",
+					"value_file",
+					path,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretHash(
+						"drone_secret.secret",
+						"value",
+						"apiVersion: v2\n",
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestSecretValueBase64(t *testing.T) {
+	value := "\x00\x01binary\xff"
+	encoded := base64.StdEncoding.EncodeToString([]byte(value))
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretConfigSource(
+					testDroneUser,
+					"repository-1",
+					"certificate",
+					"value_base64",
+					encoded,
+				),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretHash(
+						"drone_secret.secret",
+						"value",
+						value,
+					),
+					testCheckSecretHash(
+						"drone_secret.secret",
+						"value_base64",
+						encoded,
+					),
+				),
+			},
+		},
+	})
+}
+
+func testSecretConfigPullRequest(user, repo string, pullRequest, pullRequestPush bool) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secret" "secret" {
+      repository                 = "${drone_repo.repo.repository}"
+      name                       = "password"
+      value                      = "1234567890"
+      allow_pull_request         = %t
+      allow_push_on_pull_request = %t
+    }
+    `,
+		user,
+		repo,
+		pullRequest,
+		pullRequestPush,
+	)
+}
+
+func TestSecretPullRequest(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretConfigPullRequest(testDroneUser, "repository-1", true, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"allow_pull_request",
+						"true",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"allow_push_on_pull_request",
+						"false",
+					),
+					testSecretPullRequest("repository-1", "password", true, false),
+				),
+			},
+			{
+				Config: testSecretConfigPullRequest(testDroneUser, "repository-1", false, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"allow_pull_request",
+						"false",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_secret.secret",
+						"allow_push_on_pull_request",
+						"true",
+					),
+					testSecretPullRequest("repository-1", "password", false, true),
+				),
+			},
+		},
+	})
+}
+
+func testSecretPullRequest(repo, name string, pullRequest, pullRequestPush bool) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		client := testProvider.Meta().(*providerConfig).client
+
+		secret, err := client.SecretInfo(testDroneUser, repo, name)
+
+		if err != nil {
+			return err
+		}
+
+		if secret.PullRequest != pullRequest || secret.PullRequestPush != pullRequestPush {
+			return fmt.Errorf(
+				"Expected pull_request %t and pull_request_push %t, got %t and %t",
+				pullRequest,
+				pullRequestPush,
+				secret.PullRequest,
+				secret.PullRequestPush,
+			)
+		}
+
+		return nil
+	}
+}
+
+func TestCreateSecret(t *testing.T) {
+	dir := t.TempDir()
+
+	large := filepath.Join(dir, "large")
+	ioutil.WriteFile(large, []byte(strings.Repeat("x", maxSecretSize+1)), 0600)
+
+	server := newTestServer("octocat", testServerToken)
+	defer server.Close()
+
+	client := newClient(server.URL, http.DefaultClient)
+
+	for _, test := range []struct {
+		name     string
+		raw      map[string]interface{}
+		value    string
+		is_error bool
+	}{
+		{"value", map[string]interface{}{"value": "password"}, "password", false},
+		{"base64", map[string]interface{}{"value_base64": "cGFzc3dvcmQ="}, "password", false},
+		{"invalid base64", map[string]interface{}{"value_base64": "not base64!"}, "", true},
+		{"missing file", map[string]interface{}{"value_file": filepath.Join(dir, "missing")}, "", true},
+		{"large file", map[string]interface{}{"value_file": large}, "", true},
+		{"large value", map[string]interface{}{"value": strings.Repeat("x", maxSecretSize+1)}, "", true},
+		{"no value", map[string]interface{}{}, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.raw["repository"] = "octocat/hello-world"
+			test.raw["name"] = "password"
+
+			data := schema.TestResourceDataRaw(t, resourceSecret().Schema, test.raw)
+			data.MarkNewResource()
+
+			secret, err := createSecret(client, data)
+
+			if test.is_error {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if secret.Value != test.value {
+				t.Errorf("expected value %q, got %q", test.value, secret.Value)
+			}
+		})
+	}
+}
+
+func testSecretExists(name string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		client := testProvider.Meta().(*providerConfig).client
+
+		resource, ok := state.RootModule().Resources[name]
+
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", name)
+		}
+
+		owner, repo, err := parseRepo(resource.Primary.Attributes["repository"])
+
+		if err != nil {
+			return err
+		}
+
+		_, err = client.SecretInfo(owner, repo, resource.Primary.Attributes["name"])
+
+		return err
+	}
+}
+
 func testSecretDestroy(state *terraform.State) error {
-	client := testProvider.Meta().(drone.Client)
+	client := testProvider.Meta().(*providerConfig).client
 
 	for _, resource := range state.RootModule().Resources {
 		if resource.Type != "drone_secret" {
@@ -107,7 +563,7 @@ func testSecretDestroy(state *terraform.State) error {
 			return err
 		}
 
-		err = client.SecretDelete(owner, repo, resource.Primary.Attributes["name"])
+		err = client.SecretInfoDelete(owner, repo, resource.Primary.Attributes["name"])
 
 		if err == nil {
 			return fmt.Errorf(
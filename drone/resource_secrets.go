@@ -0,0 +1,442 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"sort"
+)
+
+func resourceSecrets() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRepository,
+			},
+			"secret": {
+				// a list rather than a set, the key of a set element would be
+				// derived from the value and duplicate names would collapse.
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSecretDiff("value_version"),
+						},
+						"value_version": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"images": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"events": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice(validSecretEvents, true),
+							},
+						},
+					},
+				},
+			},
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: resourceSecretsImport,
+		},
+
+		CustomizeDiff: resourceSecretsCustomizeDiff,
+
+		Create: resourceSecretsCreate,
+		Read:   resourceSecretsRead,
+		Update: resourceSecretsUpdate,
+		Delete: resourceSecretsDelete,
+	}
+}
+
+func resourceSecretsCreate(data *schema.ResourceData, meta interface{}) error {
+	config := meta.(*providerConfig)
+	client := config.client
+
+	owner, repo, err := config.parseRepo(data.Get("repository").(string))
+
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s", owner, repo))
+
+	return reconcileSecrets(data, client, owner, repo)
+}
+
+func resourceSecretsRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	secrets, err := client.SecretList(owner, repo)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readSecrets(data, owner, repo, secrets, err)
+}
+
+func resourceSecretsUpdate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	return reconcileSecrets(data, client, owner, repo)
+}
+
+func resourceSecretsDelete(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return err
+	}
+
+	secrets, err := client.SecretList(owner, repo)
+
+	if isNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	existing := secretsByName(secrets)
+
+	for _, v := range data.Get("secret").([]interface{}) {
+		name := v.(map[string]interface{})["name"].(string)
+
+		if _, ok := existing[name]; !ok {
+			continue
+		}
+
+		if err := client.SecretInfoDelete(owner, repo, name); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceSecretsCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeRepositoryDiff(diff, meta); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+
+	for _, v := range diff.Get("secret").([]interface{}) {
+		name := v.(map[string]interface{})["name"].(string)
+
+		if names[name] {
+			return fmt.Errorf("Error: Secret %s is declared more than once.", name)
+		}
+
+		names[name] = true
+	}
+
+	return nil
+}
+
+// resourceSecretsImport takes over every secret of the repository, their
+// values are unknown so the next apply writes them.
+func resourceSecretsImport(data *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	client := meta.(*providerConfig).client
+
+	owner, repo, err := parseRepo(data.Id())
+
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := client.SecretList(owner, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, 0, len(secrets))
+
+	for _, secret := range secrets {
+		items = append(items, flattenSecretsItem(secret, "", ""))
+	}
+
+	data.Set("secret", items)
+
+	return []*schema.ResourceData{data}, nil
+}
+
+// reconcileSecrets brings the repository secrets in line with the
+// configuration using a single list request, creating, updating and deleting
+// only the secrets that differ.
+func reconcileSecrets(data *schema.ResourceData, client *apiClient, owner, repo string) error {
+	secrets, err := client.SecretList(owner, repo)
+
+	if err != nil {
+		return err
+	}
+
+	existing := secretsByName(secrets)
+
+	o, n := data.GetChange("secret")
+
+	previous := map[string]map[string]interface{}{}
+
+	for _, v := range o.([]interface{}) {
+		item := v.(map[string]interface{})
+		previous[item["name"].(string)] = item
+	}
+
+	items := []interface{}{}
+	done := map[string]bool{}
+
+	// on failure the secrets that were not reconciled keep their previous
+	// state, so that no plain value is recorded.
+	fail := func(err error) error {
+		for name, item := range previous {
+			if !done[name] {
+				items = append(items, item)
+			}
+		}
+
+		data.Set("secret", items)
+
+		return err
+	}
+
+	for _, v := range n.([]interface{}) {
+		item := v.(map[string]interface{})
+		name := item["name"].(string)
+		value := item["value"].(string)
+		version := item["value_version"].(string)
+
+		secret := createSecretsItem(client, item)
+
+		prev, managed := previous[name]
+
+		write := true
+
+		if managed {
+			hash := prev["value"].(string)
+			write = prev["value_version"].(string) != version ||
+				(value != hash && !secretHashMatches(hash, value))
+		}
+
+		if !write {
+			secret.Value = ""
+		}
+
+		current, exists := existing[name]
+
+		var written *secretInfo
+
+		switch {
+		case !exists && !write:
+			return fail(fmt.Errorf("Error: Secret %s was deleted, its value is unknown.", name))
+		case !exists:
+			written, err = client.SecretInfoCreate(owner, repo, secret)
+		case write || !secretMatches(current, secret):
+			written, err = client.SecretInfoUpdate(owner, repo, secret)
+		}
+
+		if err != nil {
+			return fail(err)
+		}
+
+		if written != nil {
+			current = &written.Secret
+		}
+
+		// a value that was not changed is only known by its hash.
+		hash := value
+
+		if !isSecretHash(value) {
+			if hash, err = newSecretHash(value); err != nil {
+				return fail(err)
+			}
+		}
+
+		done[name] = true
+		items = append(items, flattenSecretsItem(current, hash, version))
+	}
+
+	for name := range previous {
+		if done[name] {
+			continue
+		}
+
+		if _, ok := existing[name]; ok {
+			if err := client.SecretInfoDelete(owner, repo, name); err != nil && !isNotFound(err) {
+				return fail(err)
+			}
+		}
+
+		done[name] = true
+	}
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("secret", items)
+
+	return nil
+}
+
+func readSecrets(data *schema.ResourceData, owner, repo string, secrets []*drone.Secret, err error) error {
+	if err != nil {
+		return err
+	}
+
+	existing := secretsByName(secrets)
+
+	items := []interface{}{}
+
+	// only managed secrets are read, a secret deleted outside of terraform
+	// drops out of state and is created again.
+	for _, v := range data.Get("secret").([]interface{}) {
+		item := v.(map[string]interface{})
+
+		secret, ok := existing[item["name"].(string)]
+
+		if !ok {
+			continue
+		}
+
+		items = append(items, flattenSecretsItem(
+			secret,
+			item["value"].(string),
+			item["value_version"].(string),
+		))
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s", owner, repo))
+
+	data.Set("repository", fmt.Sprintf("%s/%s", owner, repo))
+	data.Set("secret", items)
+
+	return nil
+}
+
+func createSecretsItem(client *apiClient, item map[string]interface{}) (secret *secretInfo) {
+	secret = &secretInfo{
+		Secret: drone.Secret{
+			Name:   item["name"].(string),
+			Value:  item["value"].(string),
+			Images: stringSetList(item["images"].(*schema.Set)),
+			Events: stringSetList(item["events"].(*schema.Set)),
+		},
+	}
+
+	// the events and images are only sent to servers that use them, as for
+	// drone_secret.
+	if !client.supports(capabilitySecretFilters) {
+		secret.Events = nil
+		secret.Images = nil
+	} else if len(secret.Events) == 0 {
+		secret.Events = defaultSecretEvents
+	}
+
+	return
+}
+
+func flattenSecretsItem(secret *drone.Secret, hash, version string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":          secret.Name,
+		"value":         hash,
+		"value_version": version,
+		"images":        schema.NewSet(schema.HashString, stringListInterface(secret.Images)),
+		"events":        schema.NewSet(schema.HashString, stringListInterface(secret.Events)),
+	}
+}
+
+func secretsByName(secrets []*drone.Secret) map[string]*drone.Secret {
+	names := make(map[string]*drone.Secret, len(secrets))
+
+	for _, secret := range secrets {
+		names[secret.Name] = secret
+	}
+
+	return names
+}
+
+// secretMatches reports whether the secret on the server has the images and
+// events wanted.
+func secretMatches(current *drone.Secret, wanted *secretInfo) bool {
+	return stringSetEqual(current.Images, wanted.Images) &&
+		stringSetEqual(current.Events, wanted.Events)
+}
+
+func stringSetList(set *schema.Set) []string {
+	list := []string{}
+
+	for _, v := range set.List() {
+		list = append(list, v.(string))
+	}
+
+	return list
+}
+
+func stringListInterface(list []string) []interface{} {
+	out := make([]interface{}, 0, len(list))
+
+	for _, v := range list {
+		out = append(out, v)
+	}
+
+	return out
+}
+
+func stringSetEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+
+	sort.Strings(a)
+	sort.Strings(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,354 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/drone/drone-go/drone"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func testSecretsConfigBasic(user, repo string, secrets map[string]string) string {
+	names := make([]string, 0, len(secrets))
+
+	for name := range secrets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	blocks := ""
+
+	for _, name := range names {
+		blocks += fmt.Sprintf(`
+      secret {
+        name  = "%s"
+        value = "%s"
+      }
+      `, name, secrets[name])
+	}
+
+	return fmt.Sprintf(`
+    resource "drone_repo" "repo" {
+      repository = "%s/%s"
+    }
+
+    resource "drone_secrets" "secrets" {
+      repository = "${drone_repo.repo.repository}"
+      %s
+    }
+    `,
+		user,
+		repo,
+		blocks,
+	)
+}
+
+func testSecretsConfigDuplicate(user, repo string) string {
+	return fmt.Sprintf(`
+    resource "drone_secrets" "secrets" {
+      repository = "%s/%s"
+
+      secret {
+        name  = "password"
+        value = "1234567890"
+      }
+
+      secret {
+        name  = "password"
+        value = "0987654321"
+      }
+    }
+    `, user, repo)
+}
+
+func TestSecrets(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretsConfigBasic(
+					testDroneUser,
+					"repository-1",
+					map[string]string{
+						"username": "octocat",
+						"password": "1234567890",
+					},
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secrets.secrets",
+						"repository",
+						fmt.Sprintf("%s/repository-1", testDroneUser),
+					),
+					resource.TestCheckResourceAttr(
+						"drone_secrets.secrets",
+						"secret.#",
+						"2",
+					),
+					testCheckSecretHash(
+						"drone_secrets.secrets",
+						"secret.0.value",
+						"1234567890",
+					),
+					testSecretsNames(
+						"repository-1",
+						"password",
+						"username",
+					),
+				),
+			},
+			{
+				// secrets not in the configuration are left alone.
+				PreConfig: func() {
+					client := testProvider.Meta().(*providerConfig).client
+					client.SecretCreate(testDroneUser, "repository-1", &drone.Secret{
+						Name:   "unmanaged",
+						Value:  "unmanaged",
+						Events: defaultSecretEvents,
+					})
+				},
+				Config: testSecretsConfigBasic(
+					testDroneUser,
+					"repository-1",
+					map[string]string{
+						"password": "0987654321",
+						"token":    "abcdef",
+					},
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_secrets.secrets",
+						"secret.#",
+						"2",
+					),
+					testCheckSecretHash(
+						"drone_secrets.secrets",
+						"secret.0.value",
+						"0987654321",
+					),
+					testSecretsNames(
+						"repository-1",
+						"password",
+						"token",
+						"unmanaged",
+					),
+				),
+			},
+			{
+				ResourceName:  "drone_secrets.secrets",
+				ImportState:   true,
+				ImportStateId: fmt.Sprintf("%s/repository-1", testDroneUser),
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("Expected 1 state, got %d", len(states))
+					}
+
+					if count := states[0].Attributes["secret.#"]; count != "3" {
+						return fmt.Errorf("Expected 3 secrets, got %s", count)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func testSecretsConfigSalted(user string) string {
+	return fmt.Sprintf(`
+    resource "drone_repo" "first" {
+      repository = "%s/repository-1"
+    }
+
+    resource "drone_repo" "second" {
+      repository = "%s/repository-2"
+    }
+
+    resource "drone_secrets" "first" {
+      repository = "${drone_repo.first.repository}"
+
+      secret {
+        name  = "password"
+        value = "1234567890"
+      }
+    }
+
+    resource "drone_secrets" "second" {
+      repository = "${drone_repo.second.repository}"
+
+      secret {
+        name  = "password"
+        value = "1234567890"
+      }
+    }
+    `, user, user)
+}
+
+func TestSecretsSalted(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testSecretsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testSecretsConfigSalted(testDroneUser),
+				Check: func(state *terraform.State) error {
+					// the same value under the same name hashes differently.
+					first := state.RootModule().Resources["drone_secrets.first"].Primary.Attributes["secret.0.value"]
+					second := state.RootModule().Resources["drone_secrets.second"].Primary.Attributes["secret.0.value"]
+
+					if first == second {
+						return fmt.Errorf("Expected different hashes, got %s", first)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestSecretsDuplicate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testSecretsConfigDuplicate(testDroneUser, "repository-1"),
+				ExpectError: regexp.MustCompile("Secret password is declared more than once"),
+			},
+		},
+	})
+}
+
+func TestSecretsStoredValue(t *testing.T) {
+	for _, test := range []struct {
+		name, version string
+	}{
+		{"Test 1.x server", "1.10.1"},
+		{"Test 0.8 server", "0.8.6"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(testDroneUser, testServerToken)
+			server.version = test.version
+			defer server.Close()
+
+			config := func(value string) string {
+				return testProviderConfig(server) + testSecretsConfigBasic(
+					testDroneUser,
+					"repository-1",
+					map[string]string{"password": value},
+				)
+			}
+
+			resource.Test(t, resource.TestCase{
+				PreCheck:  func() { testAccPreCheck(t) },
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: config("1234567890"),
+						Check:  testSecretsStoredValue(server, "repository-1", "password", "1234567890"),
+					},
+					{
+						Config: config("0987654321"),
+						Check:  testSecretsStoredValue(server, "repository-1", "password", "0987654321"),
+					},
+				},
+			})
+		})
+	}
+}
+
+// testSecretsStoredValue checks the value a test server stored for a secret,
+// in the field of its api version.
+func testSecretsStoredValue(server *testServer, repo, name, value string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+
+		secret, ok := server.secrets[fmt.Sprintf("%s/%s", testDroneUser, repo)][name]
+
+		if !ok {
+			return fmt.Errorf("Secret not found: %s", name)
+		}
+
+		stored := secret.Data
+
+		if server.legacy() {
+			stored = secret.Value
+		}
+
+		if stored != value {
+			return fmt.Errorf("Expected secret %s value %q, got %q", name, value, stored)
+		}
+
+		return nil
+	}
+}
+
+func testSecretsNames(repo string, names ...string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		client := testProvider.Meta().(*providerConfig).client
+
+		secrets, err := client.SecretList(testDroneUser, repo)
+
+		if err != nil {
+			return err
+		}
+
+		existing := []string{}
+
+		for _, secret := range secrets {
+			existing = append(existing, secret.Name)
+		}
+
+		sort.Strings(existing)
+
+		if strings.Join(existing, ",") != strings.Join(names, ",") {
+			return fmt.Errorf("Expected secrets %v, got %v", names, existing)
+		}
+
+		return nil
+	}
+}
+
+func testSecretsDestroy(state *terraform.State) error {
+	client := testProvider.Meta().(*providerConfig).client
+
+	for _, resource := range state.RootModule().Resources {
+		if resource.Type != "drone_secrets" {
+			continue
+		}
+
+		owner, repo, err := parseRepo(resource.Primary.Attributes["repository"])
+
+		if err != nil {
+			return err
+		}
+
+		secrets, err := client.SecretList(owner, repo)
+
+		if err != nil {
+			continue
+		}
+
+		for _, secret := range secrets {
+			if secret.Name == "password" || secret.Name == "token" {
+				return fmt.Errorf(
+					"Secret still exists: %s/%s:%s",
+					owner,
+					repo,
+					secret.Name,
+				)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"regexp"
+)
+
+func resourceTemplate() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"namespace": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringMatch(
+					regexp.MustCompile("^[^/ ]+$"),
+					"Invalid namespace (e.g. octocat)",
+				),
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringMatch(
+					regexp.MustCompile(`^[^/ ]+\.(yaml|yml|jsonnet|star)$`),
+					"Invalid template name, the extension must be .yaml, .yml, .jsonnet or .star (e.g. pipeline.yaml)",
+				),
+			},
+			"data": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Create: resourceTemplateCreate,
+		Read:   resourceTemplateRead,
+		Update: resourceTemplateUpdate,
+		Delete: resourceTemplateDelete,
+		Exists: resourceTemplateExists,
+	}
+}
+
+func resourceTemplateCreate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace := data.Get("namespace").(string)
+
+	tmpl, err := client.TemplateCreate(namespace, createTemplate(data))
+
+	return readTemplate(data, namespace, tmpl, err)
+}
+
+func resourceTemplateRead(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "pipeline.yaml")
+
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := client.Template(namespace, name)
+
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
+
+	return readTemplate(data, namespace, tmpl, err)
+}
+
+func resourceTemplateUpdate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace := data.Get("namespace").(string)
+
+	tmpl, err := client.TemplateUpdate(namespace, createTemplate(data))
+
+	return readTemplate(data, namespace, tmpl, err)
+}
+
+func resourceTemplateDelete(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "pipeline.yaml")
+
+	if err != nil {
+		return err
+	}
+
+	return client.TemplateDelete(namespace, name)
+}
+
+func resourceTemplateExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+	client := meta.(*providerConfig).client
+
+	namespace, name, err := parseOrgId(data.Id(), "pipeline.yaml")
+
+	if err != nil {
+		return false, err
+	}
+
+	tmpl, err := client.Template(namespace, name)
+
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return tmpl.Name == name, nil
+}
+
+func createTemplate(data *schema.ResourceData) (tmpl *template) {
+	tmpl = &template{
+		Namespace: data.Get("namespace").(string),
+		Name:      data.Get("name").(string),
+		Data:      data.Get("data").(string),
+	}
+
+	return
+}
+
+func readTemplate(data *schema.ResourceData, namespace string, tmpl *template, err error) error {
+	if err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%s/%s", namespace, tmpl.Name))
+
+	data.Set("namespace", namespace)
+	data.Set("name", tmpl.Name)
+	data.Set("data", tmpl.Data)
+
+	return nil
+}
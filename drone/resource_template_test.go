@@ -0,0 +1,115 @@
+package drone
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"regexp"
+	"testing"
+)
+
+func testTemplateConfigBasic(namespace, name, data string) string {
+	return fmt.Sprintf(`
+    resource "drone_template" "template" {
+      namespace = "%s"
+      name      = "%s"
+      data      = <<EOT
+%sEOT
+    }
+    `,
+		namespace,
+		name,
+		data,
+	)
+}
+
+func TestTemplate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testTemplateConfigBasic(
+					testDroneUser,
+					"pipeline.yaml",
+					"kind: pipeline\ntype: docker\nname: default\n",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_template.template",
+						"namespace",
+						testDroneUser,
+					),
+					resource.TestCheckResourceAttr(
+						"drone_template.template",
+						"name",
+						"pipeline.yaml",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_template.template",
+						"data",
+						"kind: pipeline\ntype: docker\nname: default\n",
+					),
+				),
+			},
+			{
+				Config: testTemplateConfigBasic(
+					testDroneUser,
+					"pipeline.yaml",
+					"kind: pipeline\ntype: docker\nname: build\n",
+				),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_template.template",
+						"data",
+						"kind: pipeline\ntype: docker\nname: build\n",
+					),
+				),
+			},
+			{
+				ResourceName:      "drone_template.template",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestTemplateInvalidName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testTemplateConfigBasic(testDroneUser, "pipeline.txt", "kind: pipeline\n"),
+				ExpectError: regexp.MustCompile("Invalid template name"),
+			},
+		},
+	})
+}
+
+func testTemplateDestroy(state *terraform.State) error {
+	client := testProvider.Meta().(*providerConfig).client
+
+	for _, resource := range state.RootModule().Resources {
+		if resource.Type != "drone_template" {
+			continue
+		}
+
+		err := client.TemplateDelete(
+			resource.Primary.Attributes["namespace"],
+			resource.Primary.Attributes["name"],
+		)
+
+		if err == nil {
+			return fmt.Errorf(
+				"Template still exists: %s:%s",
+				resource.Primary.Attributes["namespace"],
+				resource.Primary.Attributes["name"],
+			)
+		}
+	}
+
+	return nil
+}
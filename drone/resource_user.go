@@ -2,7 +2,7 @@ package drone
 
 import (
 	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 var defaultUserEvents = []string{
@@ -19,6 +19,30 @@ func resourceUser() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"email": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"admin": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"machine": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+			},
+			"token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
 		},
 
 		Importer: &schema.ResourceImporter{
@@ -27,61 +51,103 @@ func resourceUser() *schema.Resource {
 
 		Create: resourceUserCreate,
 		Read:   resourceUserRead,
+		Update: resourceUserUpdate,
 		Delete: resourceUserDelete,
 		Exists: resourceUserExists,
 	}
 }
 
 func resourceUserCreate(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
+
+	account, err := client.UserCreate(createUser(data))
+
+	if err != nil {
+		return err
+	}
 
-	user, err := client.UserPost(createUser(data))
+	data.SetId(account.Login)
 
-	return readUser(data, user, err)
+	// the token of a machine account is only returned on creation.
+	data.Set("token", account.Token)
+
+	return resourceUserRead(data, meta)
 }
 
 func resourceUserRead(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
+
+	account, err := client.UserInfo(data.Id())
 
-	user, err := client.User(data.Id())
+	if isNotFound(err) {
+		data.SetId("")
+		return nil
+	}
 
-	return readUser(data, user, err)
+	return readUser(data, account, err)
+}
+
+func resourceUserUpdate(data *schema.ResourceData, meta interface{}) error {
+	client := meta.(*providerConfig).client
+
+	account, err := client.UserInfoUpdate(&user{
+		Login:  data.Id(),
+		Email:  data.Get("email").(string),
+		Admin:  data.Get("admin").(bool),
+		Active: data.Get("active").(bool),
+	})
+
+	return readUser(data, account, err)
 }
 
 func resourceUserDelete(data *schema.ResourceData, meta interface{}) error {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	return client.UserDel(data.Id())
 }
 
 func resourceUserExists(data *schema.ResourceData, meta interface{}) (bool, error) {
-	client := meta.(drone.Client)
+	client := meta.(*providerConfig).client
 
 	login := data.Id()
 
 	user, err := client.User(login)
 
-	exists := (user.Login == login) && (err == nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
 
-	return exists, err
+	return user.Login == login, nil
 }
 
-func createUser(data *schema.ResourceData) (user *drone.User) {
-	user = &drone.User{
-		Login: data.Get("login").(string),
+func createUser(data *schema.ResourceData) (account *user) {
+	account = &user{
+		Login:   data.Get("login").(string),
+		Email:   data.Get("email").(string),
+		Admin:   data.Get("admin").(bool),
+		Active:  data.Get("active").(bool),
+		Machine: data.Get("machine").(bool),
 	}
 
 	return
 }
 
-func readUser(data *schema.ResourceData, user *drone.User, err error) error {
+func readUser(data *schema.ResourceData, account *user, err error) error {
 	if err != nil {
 		return err
 	}
 
-	data.SetId(user.Login)
+	data.SetId(account.Login)
 
-	data.Set("login", user.Login)
+	data.Set("login", account.Login)
+	data.Set("email", account.Email)
+	data.Set("admin", account.Admin)
+	data.Set("active", account.Active)
+	data.Set("machine", account.Machine)
 
 	return nil
 }
@@ -2,9 +2,8 @@ package drone
 
 import (
 	"fmt"
-	"github.com/drone/drone-go/drone"
-	"github.com/hashicorp/terraform/helper/resource"
-	"github.com/hashicorp/terraform/terraform"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"testing"
 )
 
@@ -14,6 +13,21 @@ resource "drone_user" "octocat" {
 }
 `
 
+var testUserConfigAdmin = `
+resource "drone_user" "octocat" {
+  login = "octocat"
+  email = "octocat@example.com"
+  admin = true
+}
+`
+
+var testUserConfigMachine = `
+resource "drone_user" "robot" {
+  login   = "robot"
+  machine = true
+}
+`
+
 func TestUser(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -28,14 +42,75 @@ func TestUser(t *testing.T) {
 						"login",
 						"octocat",
 					),
+					resource.TestCheckResourceAttr(
+						"drone_user.octocat",
+						"admin",
+						"false",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_user.octocat",
+						"active",
+						"true",
+					),
+				),
+			},
+			{
+				Config: testUserConfigAdmin,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_user.octocat",
+						"email",
+						"octocat@example.com",
+					),
+					resource.TestCheckResourceAttr(
+						"drone_user.octocat",
+						"admin",
+						"true",
+					),
+				),
+			},
+			{
+				ResourceName:            "drone_user.octocat",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+		},
+	})
+}
+
+func TestUserMachine(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testProviders,
+		CheckDestroy: testUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testUserConfigMachine,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"drone_user.robot",
+						"machine",
+						"true",
+					),
+					resource.TestCheckResourceAttrSet(
+						"drone_user.robot",
+						"token",
+					),
 				),
 			},
+			{
+				ResourceName:            "drone_user.robot",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
 		},
 	})
 }
 
 func testUserDestroy(state *terraform.State) error {
-	client := testProvider.Meta().(drone.Client)
+	client := testProvider.Meta().(*providerConfig).client
 
 	for _, resource := range state.RootModule().Resources {
 		if resource.Type != "drone_user" {
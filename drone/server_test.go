@@ -0,0 +1,1057 @@
+package drone
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/drone/drone-go/drone"
+)
+
+// testServer is an in-memory stand-in for the Drone REST API. It serves the
+// endpoints used by drone.Client so acceptance tests can run without a live
+// Drone server.
+type testServer struct {
+	*httptest.Server
+
+	token string
+	login string
+
+	mutex      sync.Mutex
+	sequence   int64
+	version    string
+	synced     bool
+	remote     []string
+	users      map[string]*user
+	repos      map[string]*repoInfo
+	secrets    map[string]map[string]*secretInfo
+	registries map[string]map[string]*drone.Registry
+	builds     map[string][]*drone.Build
+	crons      map[string]map[string]*cron
+	orgSecrets map[string]map[string]*orgSecret
+	templates  map[string]map[string]*template
+}
+
+func newTestServer(login, token string) *testServer {
+	server := &testServer{
+		token:      token,
+		login:      login,
+		version:    "1.10.1",
+		remote:     []string{login + "/discovery-1", login + "/discovery-2", "octo-org/discovery-3"},
+		users:      make(map[string]*user),
+		repos:      make(map[string]*repoInfo),
+		secrets:    make(map[string]map[string]*secretInfo),
+		registries: make(map[string]map[string]*drone.Registry),
+		builds:     make(map[string][]*drone.Build),
+		crons:      make(map[string]map[string]*cron),
+		orgSecrets: make(map[string]map[string]*orgSecret),
+		templates:  make(map[string]map[string]*template),
+	}
+
+	server.users[login] = &user{
+		ID:     server.nextId(),
+		Login:  login,
+		Email:  fmt.Sprintf("%s@example.com", login),
+		Active: true,
+		Admin:  true,
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+
+	return server
+}
+
+// legacy reports whether the server emulates the Drone 0.8 api.
+func (s *testServer) legacy() bool {
+	major, _ := (&serverVersion{Version: s.version}).major()
+
+	return major < 1
+}
+
+func (s *testServer) nextId() int64 {
+	s.sequence++
+	return s.sequence
+}
+
+func (s *testServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// the version is public, as on a real server. Drone 0.8 is emulated
+	// reporting it in a header only.
+	if s.legacy() {
+		w.Header().Set(headerVersion, s.version)
+	} else if r.URL.Path == "/version" {
+		testServerWrite(w, http.StatusOK, &serverVersion{Version: s.version})
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// the path is split before it is unescaped, as the router of a real
+	// server does, so an unescaped name cannot reach the right endpoint.
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/"), "/")
+
+	for i, part := range parts {
+		parts[i], _ = url.PathUnescape(part)
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "user":
+		s.serveSelf(w, r)
+	case len(parts) == 2 && parts[0] == "user" && parts[1] == "repos":
+		s.serveRepos(w, r)
+	case len(parts) == 1 && parts[0] == "users":
+		s.serveUsers(w, r)
+	case len(parts) == 2 && parts[0] == "users":
+		s.serveUser(w, r, parts[1])
+	case len(parts) == 2 && parts[0] == "secrets":
+		s.serveOrgSecrets(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "secrets":
+		s.serveOrgSecret(w, r, parts[1], parts[2])
+	case len(parts) == 2 && parts[0] == "templates":
+		s.serveTemplates(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "templates":
+		s.serveTemplate(w, r, parts[1], parts[2])
+	case len(parts) == 3 && parts[0] == "repos":
+		s.serveRepo(w, r, parts[1], parts[2])
+	case len(parts) >= 4 && parts[0] == "repos":
+		slug := fmt.Sprintf("%s/%s", parts[1], parts[2])
+
+		if _, ok := s.repos[slug]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch {
+		case len(parts) == 4 && parts[3] == "secrets":
+			s.serveSecrets(w, r, slug)
+		case len(parts) == 5 && parts[3] == "secrets":
+			s.serveSecret(w, r, slug, parts[4])
+		case len(parts) == 4 && parts[3] == "registry" && s.legacy():
+			s.serveRegistries(w, r, slug)
+		case len(parts) >= 5 && parts[3] == "registry" && s.legacy():
+			s.serveRegistry(w, r, slug, strings.Join(parts[4:], "/"))
+		case len(parts) == 4 && parts[3] == "builds":
+			s.serveBuilds(w, r, slug)
+		case len(parts) == 5 && parts[3] == "builds":
+			s.serveBuild(w, r, slug, parts[4])
+		case len(parts) == 6 && parts[3] == "builds" && parts[5] == "promote":
+			s.servePromote(w, r, slug, parts[4])
+		case len(parts) == 4 && parts[3] == "cron":
+			s.serveCrons(w, r, slug)
+		case len(parts) == 5 && parts[3] == "cron":
+			s.serveCron(w, r, slug, parts[4])
+		default:
+			http.NotFound(w, r)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *testServer) serveSelf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		testServerMethodNotAllowed(w)
+		return
+	}
+
+	testServerWrite(w, http.StatusOK, testServerUser(s.users[s.login]))
+}
+
+func (s *testServer) serveRepos(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		s.synced = true
+	default:
+		testServerMethodNotAllowed(w)
+		return
+	}
+
+	repos := make(map[string]*repoInfo, len(s.repos))
+
+	for slug, repo := range s.repos {
+		repos[slug] = repo
+	}
+
+	// repositories of the remote system are only known once synchronized,
+	// and stay inactive until activated.
+	if s.synced {
+		for _, slug := range s.remote {
+			if _, ok := repos[slug]; ok {
+				continue
+			}
+
+			parts := strings.SplitN(slug, "/", 2)
+			active := false
+
+			repos[slug] = &repoInfo{
+				Repo: drone.Repo{
+					Owner:      parts[0],
+					Name:       parts[1],
+					FullName:   slug,
+					Visibility: "public",
+				},
+				Active:    &active,
+				Namespace: parts[0],
+				Slug:      slug,
+			}
+		}
+	}
+
+	slugs := make([]string, 0, len(repos))
+
+	for slug := range repos {
+		slugs = append(slugs, slug)
+	}
+
+	sort.Strings(slugs)
+
+	list := make([]*repoInfo, 0, len(slugs))
+
+	for _, slug := range slugs {
+		list = append(list, repos[slug])
+	}
+
+	testServerWrite(w, http.StatusOK, list)
+}
+
+func (s *testServer) serveRepo(w http.ResponseWriter, r *http.Request, owner, name string) {
+	slug := fmt.Sprintf("%s/%s", owner, name)
+	repo, exists := s.repos[slug]
+
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		testServerWrite(w, http.StatusOK, repo)
+	case http.MethodPost:
+		if exists {
+			http.Error(w, "Repository is already active.", http.StatusConflict)
+			return
+		}
+
+		repo = &repoInfo{
+			Repo: drone.Repo{
+				ID:         s.nextId(),
+				Owner:      owner,
+				Name:       name,
+				FullName:   slug,
+				Kind:       "git",
+				Clone:      fmt.Sprintf("https://git.example.com/%s.git", slug),
+				Link:       fmt.Sprintf("https://git.example.com/%s", slug),
+				Branch:     "master",
+				Timeout:    60,
+				Visibility: "public",
+				AllowPull:  s.legacy(),
+				AllowPush:  s.legacy(),
+				Config:     ".drone.yml",
+			},
+			Namespace:  owner,
+			Slug:       slug,
+			HTTPURL:    fmt.Sprintf("https://git.example.com/%s.git", slug),
+			SSHURL:     fmt.Sprintf("git@git.example.com:%s.git", slug),
+			ConfigPath: ".drone.yml",
+			Signer:     fmt.Sprintf("signer-%s", slug),
+		}
+
+		repo.UID = fmt.Sprintf("%d", repo.ID)
+
+		s.repos[slug] = repo
+		s.secrets[slug] = make(map[string]*secretInfo)
+		s.registries[slug] = make(map[string]*drone.Registry)
+		s.crons[slug] = make(map[string]*cron)
+
+		testServerWrite(w, http.StatusOK, repo)
+	case http.MethodPatch:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		patch := new(repoPatch)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		// each api version rejects the settings of the other.
+		legacySettings := patch.IsGated != nil || patch.AllowPull != nil || patch.AllowPush != nil ||
+			patch.AllowDeploy != nil || patch.AllowTag != nil
+		settings := patch.Protected != nil || patch.IgnoreForks != nil || patch.IgnorePulls != nil ||
+			patch.CancelPulls != nil || patch.CancelPush != nil || patch.Throttle != nil || patch.Counter != nil
+
+		if (s.legacy() && settings) || (!s.legacy() && legacySettings) {
+			http.Error(w, "Unsupported repository setting.", http.StatusBadRequest)
+			return
+		}
+
+		if patch.Config != nil {
+			repo.Config = *patch.Config
+		}
+		if patch.ConfigPath != nil {
+			repo.ConfigPath = *patch.ConfigPath
+		}
+		if patch.Protected != nil {
+			repo.Protected = *patch.Protected
+		}
+		if patch.IgnoreForks != nil {
+			repo.IgnoreForks = *patch.IgnoreForks
+		}
+		if patch.IgnorePulls != nil {
+			repo.IgnorePulls = *patch.IgnorePulls
+		}
+		if patch.CancelPulls != nil {
+			repo.CancelPulls = *patch.CancelPulls
+		}
+		if patch.CancelPush != nil {
+			repo.CancelPush = *patch.CancelPush
+		}
+		if patch.Throttle != nil {
+			repo.Throttle = *patch.Throttle
+		}
+		if patch.Counter != nil {
+			repo.Counter = *patch.Counter
+		}
+		if patch.IsTrusted != nil {
+			repo.IsTrusted = *patch.IsTrusted
+		}
+		if patch.IsGated != nil {
+			repo.IsGated = *patch.IsGated
+		}
+		if patch.Timeout != nil {
+			repo.Timeout = *patch.Timeout
+		}
+		if patch.Visibility != nil {
+			repo.Visibility = *patch.Visibility
+			repo.IsPrivate = *patch.Visibility != "public"
+		}
+		if patch.AllowPull != nil {
+			repo.AllowPull = *patch.AllowPull
+		}
+		if patch.AllowPush != nil {
+			repo.AllowPush = *patch.AllowPush
+		}
+		if patch.AllowDeploy != nil {
+			repo.AllowDeploy = *patch.AllowDeploy
+		}
+		if patch.AllowTag != nil {
+			repo.AllowTag = *patch.AllowTag
+		}
+
+		testServerWrite(w, http.StatusOK, repo)
+	case http.MethodDelete:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		delete(s.repos, slug)
+		delete(s.secrets, slug)
+		delete(s.registries, slug)
+		delete(s.crons, slug)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveSecrets(w http.ResponseWriter, r *http.Request, slug string) {
+	switch r.Method {
+	case http.MethodGet:
+		names := make([]string, 0, len(s.secrets[slug]))
+
+		for name := range s.secrets[slug] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		secrets := make([]*secretInfo, 0, len(names))
+
+		for _, name := range names {
+			secrets = append(secrets, testServerSecret(s.secrets[slug][name]))
+		}
+
+		testServerWrite(w, http.StatusOK, secrets)
+	case http.MethodPost:
+		secret := new(secretInfo)
+
+		if !testServerRead(w, r, secret) {
+			return
+		}
+
+		if _, exists := s.secrets[slug][secret.Name]; exists {
+			http.Error(w, "Secret already exists.", http.StatusConflict)
+			return
+		}
+
+		// each api version only reads the fields it knows.
+		if s.legacy() {
+			secret.Data = ""
+			secret.PullRequest = false
+			secret.PullRequestPush = false
+		} else {
+			secret.Value = ""
+			secret.Images = nil
+			secret.Events = nil
+		}
+
+		secret.ID = s.nextId()
+
+		s.secrets[slug][secret.Name] = secret
+
+		testServerWrite(w, http.StatusOK, testServerSecret(secret))
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveSecret(w http.ResponseWriter, r *http.Request, slug, name string) {
+	secret, exists := s.secrets[slug][name]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, testServerSecret(secret))
+	case http.MethodPatch:
+		patch := new(struct {
+			drone.Secret
+
+			Data            string `json:"data"`
+			PullRequest     *bool  `json:"pull_request"`
+			PullRequestPush *bool  `json:"pull_request_push"`
+		})
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		// each api version only reads the fields it knows.
+		if s.legacy() {
+			if patch.Value != "" {
+				secret.Value = patch.Value
+			}
+			if patch.Images != nil {
+				secret.Images = patch.Images
+			}
+			if patch.Events != nil {
+				secret.Events = patch.Events
+			}
+		} else {
+			if patch.Data != "" {
+				secret.Data = patch.Data
+			}
+			if patch.PullRequest != nil {
+				secret.PullRequest = *patch.PullRequest
+			}
+			if patch.PullRequestPush != nil {
+				secret.PullRequestPush = *patch.PullRequestPush
+			}
+		}
+
+		testServerWrite(w, http.StatusOK, testServerSecret(secret))
+	case http.MethodDelete:
+		delete(s.secrets[slug], name)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveRegistries(w http.ResponseWriter, r *http.Request, slug string) {
+	switch r.Method {
+	case http.MethodGet:
+		addresses := make([]string, 0, len(s.registries[slug]))
+
+		for address := range s.registries[slug] {
+			addresses = append(addresses, address)
+		}
+
+		sort.Strings(addresses)
+
+		registries := make([]*drone.Registry, 0, len(addresses))
+
+		for _, address := range addresses {
+			registries = append(registries, testServerRegistry(s.registries[slug][address]))
+		}
+
+		testServerWrite(w, http.StatusOK, registries)
+	case http.MethodPost:
+		registry := new(drone.Registry)
+
+		if !testServerRead(w, r, registry) {
+			return
+		}
+
+		if _, exists := s.registries[slug][registry.Address]; exists {
+			http.Error(w, "Registry already exists.", http.StatusConflict)
+			return
+		}
+
+		registry.ID = s.nextId()
+
+		s.registries[slug][registry.Address] = registry
+
+		testServerWrite(w, http.StatusOK, testServerRegistry(registry))
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveRegistry(w http.ResponseWriter, r *http.Request, slug, address string) {
+	registry, exists := s.registries[slug][address]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, testServerRegistry(registry))
+	case http.MethodPatch:
+		patch := new(drone.Registry)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		if patch.Username != "" {
+			registry.Username = patch.Username
+		}
+		if patch.Password != "" {
+			registry.Password = patch.Password
+		}
+
+		testServerWrite(w, http.StatusOK, testServerRegistry(registry))
+	case http.MethodDelete:
+		delete(s.registries[slug], address)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveBuilds(w http.ResponseWriter, r *http.Request, slug string) {
+	switch r.Method {
+	case http.MethodGet:
+		builds := make([]*drone.Build, 0, len(s.builds[slug]))
+
+		for i := len(s.builds[slug]) - 1; i >= 0; i-- {
+			builds = append(builds, s.builds[slug][i])
+		}
+
+		testServerWrite(w, http.StatusOK, builds)
+	case http.MethodPost:
+		query := r.URL.Query()
+
+		build := s.newBuild(slug, "custom", query.Get("branch"), query.Get("commit"))
+
+		testServerWrite(w, http.StatusOK, build)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveBuild(w http.ResponseWriter, r *http.Request, slug, number string) {
+	var build *drone.Build
+
+	if number == "latest" {
+		branch := r.URL.Query().Get("branch")
+
+		for _, b := range s.builds[slug] {
+			if branch == "" || b.Branch == branch {
+				build = b
+			}
+		}
+	} else if n, err := strconv.Atoi(number); err == nil && n > 0 && n <= len(s.builds[slug]) {
+		build = s.builds[slug][n-1]
+	}
+
+	if build == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.advanceBuild(build)
+
+		testServerWrite(w, http.StatusOK, build)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) servePromote(w http.ResponseWriter, r *http.Request, slug, number string) {
+	if r.Method != http.MethodPost {
+		testServerMethodNotAllowed(w)
+		return
+	}
+
+	n, err := strconv.Atoi(number)
+
+	if err != nil || n < 1 || n > len(s.builds[slug]) {
+		http.NotFound(w, r)
+		return
+	}
+
+	source := s.builds[slug][n-1]
+
+	build := s.newBuild(slug, "promote", source.Branch, source.Commit)
+	build.Parent = source.Number
+	build.Deploy = r.URL.Query().Get("target")
+
+	testServerWrite(w, http.StatusOK, build)
+}
+
+// newBuild queues a build. Builds of the branch "failing" fail, others
+// succeed. The commit is resolved to a full sha, as a short sha would be.
+func (s *testServer) newBuild(slug, event, branch, commit string) *drone.Build {
+	if branch == "" {
+		branch = s.repos[slug].Branch
+	}
+
+	id := s.nextId()
+
+	if len(commit) < 40 {
+		commit = (commit + fmt.Sprintf("%040x", id))[:40]
+	}
+
+	build := &drone.Build{
+		ID:      id,
+		Number:  len(s.builds[slug]) + 1,
+		Event:   event,
+		Status:  drone.StatusPending,
+		Commit:  commit,
+		Branch:  branch,
+		Ref:     "refs/heads/" + branch,
+		Created: id,
+	}
+
+	build.Link = fmt.Sprintf("%s/%s/%d", s.URL, slug, build.Number)
+
+	s.builds[slug] = append(s.builds[slug], build)
+
+	return build
+}
+
+// advanceBuild moves a build on by one step each time it is read, so that
+// waiting for it takes a few requests.
+func (s *testServer) advanceBuild(build *drone.Build) {
+	switch build.Status {
+	case drone.StatusPending:
+		build.Status = drone.StatusRunning
+		build.Started = s.nextId()
+
+		switch build.Branch {
+		case "waiting":
+			build.Status = statusWaitingOnDependencies
+		case "blocked":
+			build.Status = drone.StatusBlocked
+		}
+	case statusWaitingOnDependencies:
+		build.Status = drone.StatusRunning
+	case drone.StatusRunning:
+		build.Status = drone.StatusSuccess
+
+		if build.Branch == "failing" {
+			build.Status = drone.StatusFailure
+		}
+
+		build.Finished = s.nextId()
+	}
+}
+
+func (s *testServer) serveCrons(w http.ResponseWriter, r *http.Request, slug string) {
+	switch r.Method {
+	case http.MethodGet:
+		names := make([]string, 0, len(s.crons[slug]))
+
+		for name := range s.crons[slug] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		jobs := make([]*cron, 0, len(names))
+
+		for _, name := range names {
+			jobs = append(jobs, s.crons[slug][name])
+		}
+
+		testServerWrite(w, http.StatusOK, jobs)
+	case http.MethodPost:
+		job := new(cron)
+
+		if !testServerRead(w, r, job) {
+			return
+		}
+
+		if _, exists := s.crons[slug][job.Name]; exists {
+			http.Error(w, "Cron job already exists.", http.StatusConflict)
+			return
+		}
+
+		job.ID = s.nextId()
+		job.Event = "push"
+
+		s.crons[slug][job.Name] = job
+
+		testServerWrite(w, http.StatusOK, job)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveCron(w http.ResponseWriter, r *http.Request, slug, name string) {
+	job, exists := s.crons[slug][name]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, job)
+	case http.MethodPatch:
+		patch := new(cronPatch)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		if patch.Expr != nil {
+			job.Expr = *patch.Expr
+		}
+		if patch.Branch != nil {
+			job.Branch = *patch.Branch
+		}
+		if patch.Disabled != nil {
+			job.Disabled = *patch.Disabled
+		}
+
+		testServerWrite(w, http.StatusOK, job)
+	case http.MethodDelete:
+		delete(s.crons[slug], name)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveOrgSecrets(w http.ResponseWriter, r *http.Request, namespace string) {
+	switch r.Method {
+	case http.MethodGet:
+		names := make([]string, 0, len(s.orgSecrets[namespace]))
+
+		for name := range s.orgSecrets[namespace] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		secrets := make([]*orgSecret, 0, len(names))
+
+		for _, name := range names {
+			secrets = append(secrets, testServerOrgSecret(s.orgSecrets[namespace][name]))
+		}
+
+		testServerWrite(w, http.StatusOK, secrets)
+	case http.MethodPost:
+		secret := new(orgSecret)
+
+		if !testServerRead(w, r, secret) {
+			return
+		}
+
+		if _, exists := s.orgSecrets[namespace][secret.Name]; exists {
+			http.Error(w, "Secret already exists.", http.StatusConflict)
+			return
+		}
+
+		if s.orgSecrets[namespace] == nil {
+			s.orgSecrets[namespace] = make(map[string]*orgSecret)
+		}
+
+		secret.ID = s.nextId()
+		secret.Namespace = namespace
+
+		s.orgSecrets[namespace][secret.Name] = secret
+
+		testServerWrite(w, http.StatusOK, testServerOrgSecret(secret))
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveOrgSecret(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	secret, exists := s.orgSecrets[namespace][name]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, testServerOrgSecret(secret))
+	case http.MethodPatch:
+		patch := new(orgSecret)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		if patch.Data != "" {
+			secret.Data = patch.Data
+		}
+
+		secret.PullRequest = patch.PullRequest
+		secret.PullRequestPush = patch.PullRequestPush
+
+		testServerWrite(w, http.StatusOK, testServerOrgSecret(secret))
+	case http.MethodDelete:
+		delete(s.orgSecrets[namespace], name)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveTemplates(w http.ResponseWriter, r *http.Request, namespace string) {
+	switch r.Method {
+	case http.MethodGet:
+		names := make([]string, 0, len(s.templates[namespace]))
+
+		for name := range s.templates[namespace] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		templates := make([]*template, 0, len(names))
+
+		for _, name := range names {
+			templates = append(templates, s.templates[namespace][name])
+		}
+
+		testServerWrite(w, http.StatusOK, templates)
+	case http.MethodPost:
+		tmpl := new(template)
+
+		if !testServerRead(w, r, tmpl) {
+			return
+		}
+
+		if _, exists := s.templates[namespace][tmpl.Name]; exists {
+			http.Error(w, "Template already exists.", http.StatusConflict)
+			return
+		}
+
+		if s.templates[namespace] == nil {
+			s.templates[namespace] = make(map[string]*template)
+		}
+
+		tmpl.ID = s.nextId()
+		tmpl.Namespace = namespace
+
+		s.templates[namespace][tmpl.Name] = tmpl
+
+		testServerWrite(w, http.StatusOK, tmpl)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveTemplate(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	tmpl, exists := s.templates[namespace][name]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, tmpl)
+	case http.MethodPatch:
+		patch := new(template)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		if patch.Data != "" {
+			tmpl.Data = patch.Data
+		}
+
+		testServerWrite(w, http.StatusOK, tmpl)
+	case http.MethodDelete:
+		delete(s.templates[namespace], name)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		logins := make([]string, 0, len(s.users))
+
+		for login := range s.users {
+			logins = append(logins, login)
+		}
+
+		sort.Strings(logins)
+
+		accounts := make([]*user, 0, len(logins))
+
+		for _, login := range logins {
+			accounts = append(accounts, testServerUser(s.users[login]))
+		}
+
+		testServerWrite(w, http.StatusOK, accounts)
+	case http.MethodPost:
+		account := new(user)
+
+		if !testServerRead(w, r, account) {
+			return
+		}
+
+		if _, exists := s.users[account.Login]; exists {
+			http.Error(w, "User already exists.", http.StatusConflict)
+			return
+		}
+
+		account.ID = s.nextId()
+		account.Token = ""
+
+		if account.Machine {
+			account.Token = fmt.Sprintf("machine-token-%d", account.ID)
+		}
+
+		s.users[account.Login] = account
+
+		testServerWrite(w, http.StatusOK, account)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+func (s *testServer) serveUser(w http.ResponseWriter, r *http.Request, login string) {
+	account, exists := s.users[login]
+
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		testServerWrite(w, http.StatusOK, testServerUser(account))
+	case http.MethodPatch:
+		patch := new(user)
+
+		if !testServerRead(w, r, patch) {
+			return
+		}
+
+		account.Admin = patch.Admin
+		account.Active = patch.Active
+
+		if patch.Email != "" {
+			account.Email = patch.Email
+		}
+
+		testServerWrite(w, http.StatusOK, testServerUser(account))
+	case http.MethodDelete:
+		delete(s.users, login)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		testServerMethodNotAllowed(w)
+	}
+}
+
+// testServerUser returns a copy of the user without its token, which Drone
+// only returns when a machine account is created.
+func testServerUser(account *user) *user {
+	out := *account
+	out.Token = ""
+	return &out
+}
+
+// testServerSecret returns a copy of the secret without its value, the way
+// Drone never echoes secret values back to clients.
+func testServerSecret(secret *secretInfo) *secretInfo {
+	out := *secret
+	out.Value = ""
+	out.Data = ""
+	return &out
+}
+
+// testServerOrgSecret returns a copy of the organization secret without its
+// value.
+func testServerOrgSecret(secret *orgSecret) *orgSecret {
+	out := *secret
+	out.Data = ""
+	return &out
+}
+
+// testServerRegistry returns a copy of the registry without its password.
+func testServerRegistry(registry *drone.Registry) *drone.Registry {
+	out := *registry
+	out.Password = ""
+	return &out
+}
+
+func testServerRead(w http.ResponseWriter, r *http.Request, in interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func testServerWrite(w http.ResponseWriter, status int, out interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(out)
+}
+
+func testServerMethodNotAllowed(w http.ResponseWriter) {
+	http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+}
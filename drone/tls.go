@@ -0,0 +1,87 @@
+package drone
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// tlsOptions holds the provider settings used to connect to a Drone server
+// with an internal certificate authority or mutual TLS.
+type tlsOptions struct {
+	caFile             string
+	caPEM              string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+}
+
+func newTLSConfig(options tlsOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: options.insecureSkipVerify,
+	}
+
+	if options.caFile != "" || options.caPEM != "" {
+		pool, err := x509.SystemCertPool()
+
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		ca := []byte(options.caPEM)
+
+		if options.caFile != "" {
+			ca, err = ioutil.ReadFile(options.caFile)
+
+			if err != nil {
+				return nil, fmt.Errorf("Error: Failed to read ca_file: %s", err)
+			}
+		}
+
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("Error: No certificates found in the certificate authority.")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if (options.clientCert == "") != (options.clientKey == "") {
+		return nil, fmt.Errorf("Error: client_cert and client_key must be set together.")
+	}
+
+	if options.clientCert != "" {
+		cert, err := readPEM(options.clientCert)
+
+		if err != nil {
+			return nil, fmt.Errorf("Error: Failed to read client_cert: %s", err)
+		}
+
+		key, err := readPEM(options.clientKey)
+
+		if err != nil {
+			return nil, fmt.Errorf("Error: Failed to read client_key: %s", err)
+		}
+
+		certificate, err := tls.X509KeyPair(cert, key)
+
+		if err != nil {
+			return nil, fmt.Errorf("Error: Invalid client certificate: %s", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// readPEM returns the given PEM content, or the content of the file when
+// given a path.
+func readPEM(str string) ([]byte, error) {
+	if strings.Contains(str, "-----BEGIN") {
+		return []byte(str), nil
+	}
+
+	return ioutil.ReadFile(str)
+}
@@ -0,0 +1,168 @@
+package drone
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// testClientCertificate returns a self signed client certificate and key,
+// PEM encoded.
+func testClientCertificate(t *testing.T) (cert, key []byte) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform-provider-drone"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &private.PublicKey, private)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := x509.MarshalECPrivateKey(private)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encoded})
+
+	return
+}
+
+func testTLSServer(clientCA []byte) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(clientCA)
+
+		server.TLS = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+
+	server.StartTLS()
+
+	return server
+}
+
+func testTLSGet(url string, options tlsOptions) error {
+	config, err := newTLSConfig(options)
+
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Transport: newBaseTransport(config)}
+
+	resp, err := client.Get(url)
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func TestTLSConfig(t *testing.T) {
+	cert, key := testClientCertificate(t)
+
+	server := testTLSServer(nil)
+	defer server.Close()
+
+	mutual := testTLSServer(cert)
+	defer mutual.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	file, err := ioutil.TempFile("", "ca")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(file.Name())
+
+	file.Write(ca)
+	file.Close()
+
+	for _, test := range []struct {
+		name     string
+		url      string
+		options  tlsOptions
+		is_error bool
+	}{
+		{"Test unknown certificate authority", server.URL, tlsOptions{}, true},
+		{"Test certificate authority pem", server.URL, tlsOptions{caPEM: string(ca)}, false},
+		{"Test certificate authority file", server.URL, tlsOptions{caFile: file.Name()}, false},
+		{"Test insecure skip verify", server.URL, tlsOptions{insecureSkipVerify: true}, false},
+		{"Test invalid certificate authority", server.URL, tlsOptions{caPEM: "invalid"}, true},
+		{"Test missing certificate authority file", server.URL, tlsOptions{caFile: "/does/not/exist"}, true},
+		{
+			"Test client certificate",
+			mutual.URL,
+			tlsOptions{
+				clientCert:         string(cert),
+				clientKey:          string(key),
+				insecureSkipVerify: true,
+			},
+			false,
+		},
+		{"Test missing client certificate", mutual.URL, tlsOptions{insecureSkipVerify: true}, true},
+		{
+			"Test client certificate without key",
+			mutual.URL,
+			tlsOptions{
+				clientCert:         string(cert),
+				insecureSkipVerify: true,
+			},
+			true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := testTLSGet(test.url, test.options)
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
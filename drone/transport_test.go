@@ -0,0 +1,226 @@
+package drone
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testFlakyServer returns a server that fails the first requests by calling
+// fail, and echoes the request body of the rest.
+func testFlakyServer(failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	attempts := new(int32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(attempts, 1) <= failures {
+			fail(w)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if len(body) == 0 {
+			body = []byte("{}")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+
+	return server, attempts
+}
+
+func testRetryTransport(maxRetries int) *retryTransport {
+	transport := newRetryTransport(http.DefaultTransport, maxRetries, 10*time.Millisecond)
+	transport.minWait = time.Millisecond
+
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	for _, test := range []struct {
+		name, method string
+		failures     int32
+		fail         func(w http.ResponseWriter)
+		status       int
+		attempts     int32
+	}{
+		{
+			"Test retries service unavailable",
+			"PATCH",
+			2,
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			http.StatusOK,
+			3,
+		},
+		{
+			"Test retries bad gateway",
+			"GET",
+			1,
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			http.StatusOK,
+			2,
+		},
+		{
+			"Test retries too many requests",
+			"POST",
+			1,
+			func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			http.StatusOK,
+			2,
+		},
+		{
+			"Test gives up after max retries",
+			"GET",
+			10,
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			http.StatusServiceUnavailable,
+			4,
+		},
+		{
+			"Test does not retry post on bad gateway",
+			"POST",
+			1,
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			http.StatusBadGateway,
+			1,
+		},
+		{
+			"Test does not retry client errors",
+			"GET",
+			1,
+			func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			http.StatusNotFound,
+			1,
+		},
+		{
+			"Test retries connection resets",
+			"GET",
+			2,
+			func(w http.ResponseWriter) {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			},
+			http.StatusOK,
+			3,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server, attempts := testFlakyServer(test.failures, test.fail)
+			defer server.Close()
+
+			client := &http.Client{Transport: testRetryTransport(3)}
+
+			req, _ := http.NewRequest(test.method, server.URL, nil)
+			req.Body = ioutil.NopCloser(strings.NewReader(`{"name":"value"}`))
+
+			resp, err := client.Do(req)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.status {
+				t.Errorf("unexpected status %d", resp.StatusCode)
+			}
+
+			if *attempts != test.attempts {
+				t.Errorf("unexpected attempts %d", *attempts)
+			}
+
+			if resp.StatusCode == http.StatusOK {
+				body, _ := ioutil.ReadAll(resp.Body)
+
+				if string(body) != `{"name":"value"}` {
+					t.Errorf("unexpected body %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportClient(t *testing.T) {
+	server, attempts := testFlakyServer(2, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := newClient(server.URL, &http.Client{Transport: testRetryTransport(3)})
+
+	if _, err := client.Self(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if *attempts != 3 {
+		t.Errorf("unexpected attempts %d", *attempts)
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := newRetryTransport(http.DefaultTransport, 5, 4*time.Second)
+	transport.minWait = time.Second
+
+	for attempt, max := range []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		4 * time.Second,
+	} {
+		wait := transport.backoff(attempt, nil)
+
+		if wait < max/2 || wait > max {
+			t.Errorf("unexpected wait %s for attempt %d", wait, attempt)
+		}
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+	}
+
+	if wait := transport.backoff(0, resp); wait != 3*time.Second {
+		t.Errorf("unexpected wait %s for retry after", wait)
+	}
+
+	resp.Header.Set("Retry-After", "60")
+
+	if wait := transport.backoff(0, resp); wait != 4*time.Second {
+		t.Errorf("unexpected wait %s for capped retry after", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		name, value string
+		wait        time.Duration
+		ok          bool
+	}{
+		{"Test seconds", "120", 120 * time.Second, true},
+		{"Test zero seconds", "0", 0, true},
+		{"Test past date", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"Test empty", "", 0, false},
+		{"Test negative seconds", "-1", 0, false},
+		{"Test invalid", "soon", 0, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(test.value)
+
+			if ok != test.ok {
+				t.Errorf("unexpected ok")
+			}
+
+			if wait != test.wait {
+				t.Errorf("unexpected wait %s", wait)
+			}
+		})
+	}
+}
@@ -2,13 +2,65 @@ package drone
 
 import (
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
+// validateRepository checks a repository name, the namespace can be left out
+// when the provider has a default namespace.
+func validateRepository(v interface{}, k string) (ws []string, es []error) {
+	value := v.(string)
+
+	if value == "" || strings.Count(value, "/") > 1 || strings.ContainsAny(value, " ") ||
+		strings.HasPrefix(value, "/") || strings.HasSuffix(value, "/") {
+		es = append(es, fmt.Errorf("%s: Invalid repository (e.g. octocat/hello-world, or hello-world with a default_namespace)", k))
+	}
+
+	return
+}
+
+// customizeRepositoryDiff resolves a repository configured without its
+// namespace against the provider default_namespace, the state holds the full
+// name read from the server. Changing the default namespace replaces the
+// resource, a name without a namespace fails the plan when there is no
+// default. The repository is optional and computed in the schema so that the
+// full name can be planned, it is required here.
+func customizeRepositoryDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("repository") {
+		return nil
+	}
+
+	value := diff.Get("repository").(string)
+
+	if value == "" {
+		return fmt.Errorf("Error: repository is required.")
+	}
+
+	owner, repo, err := meta.(*providerConfig).parseRepo(value)
+
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s/%s", owner, repo)
+
+	if name == value {
+		return nil
+	}
+
+	if old, _ := diff.GetChange("repository"); old.(string) == name {
+		return diff.Clear("repository")
+	}
+
+	return diff.SetNew("repository", name)
+}
+
 func parseRepo(str string) (user, repo string, err error) {
 	parts := strings.Split(str, "/")
 
-	if len(parts) != 2 {
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 		err = fmt.Errorf("Error: Invalid repository (e.g. octocat/hello-world).")
 		return
 	}
@@ -18,21 +70,128 @@ func parseRepo(str string) (user, repo string, err error) {
 	return
 }
 
+// formatId returns the identity of a resource belonging to a repository,
+// owner/repo/name with each segment percent-encoded so that names holding a
+// slash split unambiguously (e.g. octocat/hello-world/gcr.io%2Fproject).
+// Several names are separated by commas.
+func formatId(user, repo string, names ...string) string {
+	escaped := make([]string, 0, len(names))
+
+	for _, name := range names {
+		escaped = append(escaped, url.PathEscape(name))
+	}
+
+	return fmt.Sprintf(
+		"%s/%s/%s",
+		url.PathEscape(user),
+		url.PathEscape(repo),
+		strings.Join(escaped, ","),
+	)
+}
+
+// parseIdList parses an identity written by formatId, every segment must be
+// non-empty and validly percent-encoded.
+func parseIdList(str, example string) (user, repo string, names []string, err error) {
+	invalid := fmt.Errorf(
+		"Error: Invalid identity %q, a slash or comma in a name is escaped as %%2F or %%2C (e.g. octocat/hello-world/%s).",
+		str,
+		example,
+	)
+
+	parts := strings.Split(str, "/")
+
+	if len(parts) != 3 {
+		err = invalid
+		return
+	}
+
+	segments := []string{parts[0], parts[1]}
+	segments = append(segments, strings.Split(parts[2], ",")...)
+
+	for i, segment := range segments {
+		unescaped, unescapeErr := url.PathUnescape(segment)
+
+		if unescapeErr != nil || unescaped == "" {
+			err = invalid
+			return
+		}
+
+		segments[i] = unescaped
+	}
+
+	user = segments[0]
+	repo = segments[1]
+	names = segments[2:]
+
+	return
+}
+
 func parseId(str, example string) (user, repo, id string, err error) {
+	user, repo, names, err := parseIdList(str, example)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if len(names) != 1 {
+		err = fmt.Errorf(
+			"Error: Invalid identity %q, a comma in a name is escaped as %%2C (e.g. octocat/hello-world/%s).",
+			str,
+			example,
+		)
+		return "", "", "", err
+	}
+
+	id = names[0]
+
+	return
+}
+
+func parseOrgId(str, example string) (namespace, name string, err error) {
 	parts := strings.Split(str, "/")
 
-	if len(parts) < 3 {
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
 		err = fmt.Errorf(
-			"Error: Invalid identity (e.g. octocat/hello-world/%s).",
+			"Error: Invalid identity (e.g. octocat/%s).",
 			example,
 		)
 		return
 	}
 
-	user = parts[0]
-	repo = parts[1]
+	namespace = parts[0]
+	name = parts[1]
+
+	return
+}
+
+func parseBuildId(str string) (user, repo string, number int, err error) {
+	user, repo, id, err := parseId(str, "42")
+
+	if err != nil {
+		return
+	}
 
-	id = strings.Join(parts[2:], "/")
+	number, err = strconv.Atoi(id)
+
+	if err != nil {
+		err = fmt.Errorf("Error: Invalid build number %s (e.g. %s/%s/42).", id, user, repo)
+	}
 
 	return
 }
+
+// updateSecretValue updates a resource holding a secret value in key, write
+// sends the update with the value to store. An unchanged value is only known
+// by its hash in state, it is sent empty and the server keeps the current
+// value. A written value is replaced by its hash.
+func updateSecretValue(data *schema.ResourceData, key string, write func(value string) error) error {
+	if !data.HasChange(key) {
+		return write("")
+	}
+
+	if err := write(data.Get(key).(string)); err != nil {
+		return err
+	}
+
+	return setSecretHash(data, key)
+}
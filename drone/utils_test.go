@@ -1,6 +1,9 @@
 package drone
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"strings"
 	"testing"
 )
 
@@ -13,6 +16,8 @@ func TestParseRepo(t *testing.T) {
 		{"Test another valid repository", "drone/drone", "drone", "drone", false},
 		{"Test invalid repository without slash", "foobar", "", "", true},
 		{"Test invalid repository with too many slashes", "foo/bar/baz", "", "", true},
+		{"Test invalid repository without owner", "/bar", "", "", true},
+		{"Test invalid repository without name", "foo/", "", "", true},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			user, repo, err := parseRepo(test.str)
@@ -35,3 +40,341 @@ func TestParseRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRepository(t *testing.T) {
+	for _, test := range []struct {
+		name, str string
+		is_error  bool
+	}{
+		{"Test full name", "octocat/hello-world", false},
+		{"Test name without namespace", "hello-world", false},
+		{"Test empty name", "", true},
+		{"Test too many slashes", "foo/bar/baz", true},
+		{"Test name without owner", "/bar", true},
+		{"Test name without repository", "foo/", true},
+		{"Test name with a space", "octocat/hello world", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, errs := validateRepository(test.str, "repository")
+
+			if (test.is_error == true) && (len(errs) == 0) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (len(errs) != 0) {
+				t.Errorf("unexpected error")
+			}
+		})
+	}
+}
+
+func TestCustomizeRepositoryDiff(t *testing.T) {
+	for _, test := range []struct {
+		name, namespace, state, repository, planned string
+		replaced, is_error                          bool
+	}{
+		{"Test name without namespace", "octocat", "octocat/hello-world", "hello-world", "", false, false},
+		{"Test full name", "octocat", "octocat/hello-world", "octocat/hello-world", "", false, false},
+		{"Test changed default namespace", "octo-org", "octocat/hello-world", "hello-world", "octo-org/hello-world", true, false},
+		{"Test changed full name", "", "octocat/hello-world", "octo-org/hello-world", "octo-org/hello-world", true, false},
+		{"Test new resource", "octocat", "", "hello-world", "octocat/hello-world", true, false},
+		{"Test name without default namespace", "", "octocat/hello-world", "hello-world", "", false, true},
+		{"Test new resource without default namespace", "", "", "hello-world", "", false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var state *terraform.InstanceState
+
+			if test.state != "" {
+				state = &terraform.InstanceState{
+					ID: test.state + "/nightly",
+					Attributes: map[string]string{
+						"id":         test.state + "/nightly",
+						"repository": test.state,
+						"name":       "nightly",
+						"expr":       "@daily",
+						"branch":     "master",
+					},
+				}
+			}
+
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"repository": test.repository,
+				"name":       "nightly",
+				"expr":       "@daily",
+			})
+
+			diff, err := resourceCron().Diff(state, config, &providerConfig{defaultNamespace: test.namespace})
+
+			if (test.is_error == true) && (err == nil) {
+				t.Fatalf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Fatalf("unexpected error %s", err)
+			}
+
+			if test.is_error {
+				return
+			}
+
+			var attribute *terraform.ResourceAttrDiff
+
+			// an empty diff is nil.
+			if diff != nil {
+				attribute = diff.Attributes["repository"]
+			}
+
+			if test.planned == "" {
+				if attribute != nil {
+					t.Errorf("unexpected diff %s => %s", attribute.Old, attribute.New)
+				}
+
+				return
+			}
+
+			if attribute == nil || attribute.New != test.planned {
+				t.Fatalf("expected %s to be planned", test.planned)
+			}
+
+			if attribute.RequiresNew != test.replaced {
+				t.Errorf("unexpected replacement %t", attribute.RequiresNew)
+			}
+		})
+	}
+}
+
+func TestFormatId(t *testing.T) {
+	for _, test := range []struct {
+		name, user, repo string
+		names            []string
+		id               string
+	}{
+		{"Test plain name", "octocat", "hello-world", []string{"password"}, "octocat/hello-world/password"},
+		{"Test name with a port", "octocat", "hello-world", []string{"example.com:5000"}, "octocat/hello-world/example.com:5000"},
+		{"Test name with a slash", "octocat", "hello-world", []string{"gcr.io/project"}, "octocat/hello-world/gcr.io%2Fproject"},
+		{"Test name with a comma", "octocat", "hello-world", []string{"a,b"}, "octocat/hello-world/a%2Cb"},
+		{"Test name with a percent sign", "octocat", "hello-world", []string{"100%"}, "octocat/hello-world/100%25"},
+		{"Test list of names", "octocat", "hello-world", []string{"docker.io", "gcr.io/project"}, "octocat/hello-world/docker.io,gcr.io%2Fproject"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if id := formatId(test.user, test.repo, test.names...); id != test.id {
+				t.Errorf("unexpected identity %s", id)
+			}
+		})
+	}
+}
+
+func TestParseId(t *testing.T) {
+	for _, test := range []struct {
+		name, str, user, repo, id string
+		is_error                  bool
+	}{
+		{"Test valid identity", "octocat/hello-world/password", "octocat", "hello-world", "password", false},
+		{"Test valid identity with an escaped slash", "octocat/hello-world/gcr.io%2Fproject", "octocat", "hello-world", "gcr.io/project", false},
+		{"Test valid identity with an escaped comma", "octocat/hello-world/a%2Cb", "octocat", "hello-world", "a,b", false},
+		{"Test valid identity with a port", "octocat/hello-world/example.com:5000", "octocat", "hello-world", "example.com:5000", false},
+		{"Test invalid identity without name", "octocat/hello-world", "", "", "", true},
+		{"Test invalid identity with an empty name", "octocat/hello-world/", "", "", "", true},
+		{"Test invalid identity with an empty repository", "octocat//password", "", "", "", true},
+		{"Test invalid identity with an empty owner", "/hello-world/password", "", "", "", true},
+		{"Test invalid identity with an unescaped slash", "octocat/hello-world/gcr.io/project", "", "", "", true},
+		{"Test invalid identity with an unescaped comma", "octocat/hello-world/a,b", "", "", "", true},
+		{"Test invalid identity with an invalid escape", "octocat/hello-world/100%", "", "", "", true},
+		{"Test valid identity with an escaped space", "octocat/hello-world/%20", "octocat", "hello-world", " ", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			user, repo, id, err := parseId(test.str, "password")
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if test.user != user {
+				t.Errorf("unexpected user")
+			}
+
+			if test.repo != repo {
+				t.Errorf("unexpected repo")
+			}
+
+			if test.id != id {
+				t.Errorf("unexpected id")
+			}
+		})
+	}
+}
+
+func TestParseIdList(t *testing.T) {
+	for _, test := range []struct {
+		name, str, names string
+		is_error         bool
+	}{
+		{"Test single name", "octocat/hello-world/docker.io", "docker.io", false},
+		{"Test list of names", "octocat/hello-world/docker.io,gcr.io%2Fproject", "docker.io|gcr.io/project", false},
+		{"Test invalid list with an empty name", "octocat/hello-world/docker.io,", "", true},
+		{"Test invalid list with an unescaped slash", "octocat/hello-world/docker.io,gcr.io/project", "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, names, err := parseIdList(test.str, "docker.io")
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if strings.Join(names, "|") != test.names {
+				t.Errorf("unexpected names %v", names)
+			}
+		})
+	}
+}
+
+func TestUpgradeIdState(t *testing.T) {
+	for _, test := range []struct {
+		name, listKey string
+		state         map[string]interface{}
+		id            string
+	}{
+		{
+			"Test secret identity",
+			"",
+			map[string]interface{}{"id": "octocat/hello-world/password"},
+			"octocat/hello-world/password",
+		},
+		{
+			"Test registry identity with a path",
+			"docker_config_json",
+			map[string]interface{}{"id": "octocat/hello-world/gcr.io/project"},
+			"octocat/hello-world/gcr.io%2Fproject",
+		},
+		{
+			"Test docker config registries identity",
+			"docker_config_json",
+			map[string]interface{}{
+				"id":                 "octocat/hello-world/docker.io,gcr.io/project",
+				"docker_config_json": "sha256:00:00",
+			},
+			"octocat/hello-world/docker.io,gcr.io%2Fproject",
+		},
+		{
+			"Test identity without a name",
+			"",
+			map[string]interface{}{"id": "octocat/hello-world"},
+			"octocat/hello-world",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			state, err := upgradeIdState(test.listKey)(test.state, nil)
+
+			if err != nil {
+				t.Fatalf("unexpected error")
+			}
+
+			if state["id"] != test.id {
+				t.Errorf("unexpected identity %s", state["id"])
+			}
+		})
+	}
+}
+
+func TestParseOrgId(t *testing.T) {
+	for _, test := range []struct {
+		name, str, namespace, secret string
+		is_error                     bool
+	}{
+		{"Test valid identity", "octocat/password", "octocat", "password", false},
+		{"Test invalid identity without slash", "foobar", "", "", true},
+		{"Test invalid identity with too many slashes", "foo/bar/baz", "", "", true},
+		{"Test invalid identity without name", "octocat/", "", "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			namespace, secret, err := parseOrgId(test.str, "password")
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if test.namespace != namespace {
+				t.Errorf("unexpected namespace")
+			}
+
+			if test.secret != secret {
+				t.Errorf("unexpected name")
+			}
+		})
+	}
+}
+
+func TestParseBuildId(t *testing.T) {
+	for _, test := range []struct {
+		name, str, user, repo string
+		number                int
+		is_error              bool
+	}{
+		{"Test valid identity", "octocat/hello-world/42", "octocat", "hello-world", 42, false},
+		{"Test invalid identity without number", "octocat/hello-world", "", "", 0, true},
+		{"Test invalid identity with a non numeric number", "octocat/hello-world/latest", "octocat", "hello-world", 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			user, repo, number, err := parseBuildId(test.str)
+
+			if (test.is_error == true) && (err == nil) {
+				t.Errorf("expected error")
+			}
+
+			if (test.is_error == false) && (err != nil) {
+				t.Errorf("unexpected error")
+			}
+
+			if test.user != user {
+				t.Errorf("unexpected user")
+			}
+
+			if test.repo != repo {
+				t.Errorf("unexpected repo")
+			}
+
+			if test.number != number {
+				t.Errorf("unexpected number")
+			}
+		})
+	}
+}
+
+func TestUpdateSecretValue(t *testing.T) {
+	data := schema.TestResourceDataRaw(t, resourceOrgSecret().Schema, map[string]interface{}{
+		"namespace": "octocat",
+		"name":      "password",
+		"value":     "correct horse battery staple",
+	})
+
+	var written string
+
+	err := updateSecretValue(data, "value", func(value string) error {
+		written = value
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if written != "correct horse battery staple" {
+		t.Errorf("unexpected value written %q", written)
+	}
+
+	if !secretHashMatches(data.Get("value").(string), written) {
+		t.Errorf("expected the value to be replaced by its hash")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/artisanofcode/terraform-provider-drone
+
+go 1.16
+
+require (
+	github.com/drone/drone-go v0.8.4
+	github.com/hashicorp/terraform-plugin-sdk v1.17.2
+	golang.org/x/oauth2 v0.0.0-20200902213428-5d25da1a8d43
+	gopkg.in/yaml.v2 v2.3.0
+)